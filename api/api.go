@@ -3,12 +3,16 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/http"
+	"time"
 
 	"idrm/api/internal/config"
 	"idrm/api/internal/handler"
 	"idrm/api/internal/svc"
 	"idrm/pkg/middleware"
 	"idrm/pkg/telemetry"
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/telemetry/log"
 	"idrm/pkg/validator"
 
 	"github.com/zeromicro/go-zero/core/conf"
@@ -17,6 +21,14 @@ import (
 
 var configFile = flag.String("f", "etc/api.yaml", "the config file")
 
+// auditRoutes annotates write routes with the audit record written for each request
+// (keys are the route templates registered by handler.RegisterHandlers; add new write routes here)
+var auditRoutes = middleware.AuditRoutes{
+	"POST /api/v1/category":       {Action: audit.ActionCreate, Resource: audit.ResourceCategory, CaptureBody: true},
+	"PUT /api/v1/category/:id":    {Action: audit.ActionUpdate, Resource: audit.ResourceCategory, CaptureBody: true},
+	"DELETE /api/v1/category/:id": {Action: audit.ActionDelete, Resource: audit.ResourceCategory},
+}
+
 func main() {
 	flag.Parse()
 
@@ -24,32 +36,62 @@ func main() {
 	conf.MustLoad(*configFile, &c)
 
 	// Initialize Telemetry (Logging, Tracing, Audit)
-	if err := telemetry.Init(c.Telemetry); err != nil {
+	tel, err := telemetry.Init(c.Telemetry)
+	if err != nil {
 		panic(fmt.Sprintf("failed to initialize telemetry: %v", err))
 	}
-	// defer telemetry.Close(context.Background())  // 临时注释，调试完成后恢复
-
 	// Initialize validator
 	validator.Init()
 	fmt.Println("Validator initialized successfully")
 
+	// Force quit only after the wrap-up delay, in-flight requests (bounded by Timeout) and the telemetry drain
+	wrapUp := c.Shutdown.WrapUpTime
+	if wrapUp <= 0 {
+		wrapUp = time.Second
+	}
+	if wait := wrapUp + time.Duration(c.Timeout)*time.Millisecond + telemetry.ShutdownTimeout; c.Shutdown.WaitTime < wait {
+		c.Shutdown.WaitTime = wait
+	}
+
 	// Create server
 	server := rest.MustNewServer(c.RestConf)
 	defer server.Stop()
 
 	// Register global middlewares (order matters!)
-	server.Use(middleware.Recovery())  // 1. Panic recovery
-	server.Use(middleware.RequestID()) // 2. Request ID generation
-	server.Use(middleware.Trace())     // 3. OpenTelemetry tracing
-	server.Use(middleware.CORS())      // 4. CORS handling
-	server.Use(middleware.Logger())    // 5. Request logging
+	server.Use(middleware.Recovery())     // 1. Panic recovery
+	server.Use(middleware.RequestID())    // 2. Request ID generation
+	server.Use(middleware.TraceWith(tel)) // 3. OpenTelemetry tracing
+	server.Use(middleware.CORS())         // 4. CORS handling
+
+	// 5. Request logging (masked JSON bodies up to Telemetry.Log.BodyLogLimit, 0 disables body capture)
+	server.Use(middleware.LoggerWithBody(c.Telemetry.Log.BodyLogLimit))
+
+	// 6. Audit logs for annotated write routes (user, tenant, status, duration filled automatically)
+	server.Use(middleware.AuditWith(tel, auditRoutes))
 
 	// Initialize service context
-	ctx := svc.NewServiceContext(c)
+	ctx := svc.NewServiceContext(c, tel)
 
 	// Register routes
 	handler.RegisterHandlers(server, ctx)
 
+	// Health check (telemetry state; tracing collector outages report degraded, not down)
+	server.AddRoute(rest.Route{Method: http.MethodGet, Path: telemetry.HealthPath, Handler: tel.HealthHandler()})
+
+	// Register admin routes (runtime log level, disabled when AdminToken is empty)
+	if token := c.Telemetry.Log.AdminToken; token != "" {
+		server.AddRoutes([]rest.Route{
+			{Method: http.MethodGet, Path: log.LevelPath, Handler: log.LevelHandler(token)},
+			{Method: http.MethodPut, Path: log.LevelPath, Handler: log.LevelHandler(token)},
+		})
+	}
+
+	// Route templates for trace span names and audit annotations (after all routes are registered)
+	middleware.RegisterRoutes(server.Routes())
+
 	fmt.Printf("Starting API server at %s:%d...\n", c.Host, c.Port)
-	server.Start()
+	// Start returns once in-flight requests have finished; then drain audit backlog, remote log queue,
+	// dedup summaries and buffered spans before server.Stop closes logx
+	// (undelivered audit events stay in the WAL and are resent on the next start)
+	telemetry.Serve(server.Start, telemetry.ShutdownTimeout)
 }
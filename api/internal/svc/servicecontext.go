@@ -1,36 +1,43 @@
 package svc
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	"idrm/api/internal/config"
+	"idrm/migrations"
 	"idrm/model/resource_catalog/category"
 	"idrm/pkg/db"
+	"idrm/pkg/db/migrate"
+	"idrm/pkg/telemetry"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/zeromicro/go-zero/core/logx"
-	"github.com/zeromicro/go-zero/core/stores/sqlx"
 	"gorm.io/gorm"
 )
 
 type ServiceContext struct {
 	Config config.Config
 
+	// 遥测实例（链路追踪、审计日志、Meter），由 telemetry.Init 创建
+	Telemetry *telemetry.Provider
+
 	// Model层（使用接口类型，支持自动ORM选择）
 	CategoryModel category.Model
+
+	// 资源目录数据库事务重试器（Trans/TransSQL 整体重试事务）
+	// 事务外的单条查询已由 GORM/SQLx 层按 Retry 配置自动重试，数据视图、数据理解数据库接入时同样生效
+	ResourceCatalogRetrier *db.Retrier
 }
 
-func NewServiceContext(c config.Config) *ServiceContext {
-	// 1. 初始化 sqlx 连接（作为备用）
+func NewServiceContext(c config.Config, tel *telemetry.Provider) *ServiceContext {
+	// 1. 初始化 sqlx 连接（作为备用，驱动层错误自动转换为 errorx 错误码）
 	var sqlConn *sql.DB
 	var sqlxErr error
-	dsn := buildDSN(c.DB.ResourceCatalog)
 	logx.Infof("尝试连接数据库(SQLx): %s:%d/%s", c.DB.ResourceCatalog.Host, c.DB.ResourceCatalog.Port, c.DB.ResourceCatalog.Database)
-	conn := sqlx.NewMysql(dsn)
-	sqlConn, sqlxErr = conn.RawDB()
+	sqlConn, sqlxErr = db.OpenSQL(c.DB.ResourceCatalog)
 	if sqlxErr != nil {
-		logx.Errorf("SQLx RawDB获取失败: %v, DSN: %s", sqlxErr, dsn)
+		logx.Errorf("SQLx 连接失败: %v", sqlxErr)
 		sqlConn = nil
 	} else {
 		logx.Info("SQLx 连接成功")
@@ -53,23 +60,67 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		panic(fmt.Sprintf("数据库连接失败！SQLx错误: %v, GORM错误: %v", sqlxErr, gormErr))
 	}
 
-	// 3. 使用工厂自动选择ORM（gorm优先，sqlx降级）
+	// 3. 启动时执行数据库迁移（可选）
+	if c.DB.ResourceCatalog.MigrateOnStartup {
+		mustMigrate(migrations.ResourceCatalog, sqlConn, gormDB)
+	}
+	// 数据视图、数据理解数据库尚未接入 Model，迁移时单独建立连接
+	for _, target := range []struct {
+		name   string
+		config db.Config
+	}{
+		{migrations.DataView, c.DB.DataView},
+		{migrations.DataUnderstanding, c.DB.DataUnderstanding},
+	} {
+		if target.config.MigrateOnStartup {
+			mustMigrateConfig(target.name, target.config)
+		}
+	}
+
+	// 4. 使用工厂自动选择ORM（gorm优先，sqlx降级）
 	categoryModel := category.NewModel(sqlConn, gormDB)
 
 	return &ServiceContext{
-		Config:        c,
-		CategoryModel: categoryModel,
+		Config:                 c,
+		Telemetry:              tel,
+		CategoryModel:          categoryModel,
+		ResourceCatalogRetrier: db.NewRetrier(c.DB.ResourceCatalog.Retry),
 	}
 }
 
-// buildDSN 构建 sqlx 的 DSN
-func buildDSN(cfg db.Config) string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
-		cfg.Username,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-		cfg.Charset,
-	)
+// mustMigrateConfig 按配置建立临时连接执行数据库迁移，失败时panic
+func mustMigrateConfig(name string, cfg db.Config) {
+	conn, err := db.OpenSQL(cfg)
+	if err != nil {
+		panic(fmt.Sprintf("数据库迁移失败(%s): %v", name, err))
+	}
+	defer conn.Close()
+
+	mustMigrate(name, conn, nil)
+}
+
+// mustMigrate 执行数据库迁移，失败时panic
+func mustMigrate(name string, sqlConn *sql.DB, gormDB *gorm.DB) {
+	conn := sqlConn
+	if conn == nil {
+		var err error
+		if conn, err = gormDB.DB(); err != nil {
+			panic(fmt.Sprintf("数据库迁移失败(%s): %v", name, err))
+		}
+	}
+
+	source, err := migrations.FS(name)
+	if err != nil {
+		panic(fmt.Sprintf("数据库迁移失败(%s): %v", name, err))
+	}
+	m, err := migrate.New(conn, name, source)
+	if err != nil {
+		panic(fmt.Sprintf("数据库迁移失败(%s): %v", name, err))
+	}
+
+	n, err := m.Up(context.Background())
+	if err != nil {
+		panic(fmt.Sprintf("数据库迁移失败(%s): %v", name, err))
+	}
+	logx.Infof("数据库迁移完成: %s, 执行 %d 个迁移", name, n)
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"idrm/migrations"
+	"idrm/pkg/db"
+	"idrm/pkg/db/migrate"
+
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+var (
+	configFile = flag.String("f", "api/etc/api.yaml", "the config file")
+	database   = flag.String("db", "", "database: resource_catalog/data_view/data_understanding/all (up/status default all, required for down/redo)")
+	steps      = flag.Int("n", 1, "number of migrations to revert (down)")
+)
+
+// Config 迁移工具配置（复用 API 配置文件中的 DB 段）
+type Config struct {
+	DB struct {
+		ResourceCatalog   db.Config
+		DataView          db.Config
+		DataUnderstanding db.Config
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		usage()
+		os.Exit(2)
+	}
+
+	// 回滚类命令必须显式指定数据库，避免误回滚所有库
+	if *database == "" {
+		if command == "down" || command == "redo" {
+			fmt.Fprintf(os.Stderr, "%s requires an explicit -db\n", command)
+			os.Exit(2)
+		}
+		*database = "all"
+	}
+
+	var c Config
+	conf.MustLoad(*configFile, &c)
+
+	targets := map[string]db.Config{
+		migrations.ResourceCatalog:   c.DB.ResourceCatalog,
+		migrations.DataView:          c.DB.DataView,
+		migrations.DataUnderstanding: c.DB.DataUnderstanding,
+	}
+
+	matched := false
+	for _, name := range []string{migrations.ResourceCatalog, migrations.DataView, migrations.DataUnderstanding} {
+		if *database != "all" && *database != name {
+			continue
+		}
+		matched = true
+		if err := run(command, name, targets[name]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+	if !matched {
+		fmt.Fprintf(os.Stderr, "unknown database: %s\n", *database)
+		os.Exit(2)
+	}
+}
+
+// run 对单个数据库执行迁移命令
+func run(command, name string, cfg db.Config) error {
+	source, err := migrations.FS(name)
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.OpenSQL(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	m, err := migrate.New(sqlDB, name, source)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	switch command {
+	case "up":
+		n, err := m.Up(ctx)
+		fmt.Printf("%s: applied %d migration(s)\n", name, n)
+		return err
+	case "down":
+		n, err := m.Down(ctx, *steps)
+		fmt.Printf("%s: reverted %d migration(s)\n", name, n)
+		return err
+	case "redo":
+		return m.Redo(ctx)
+	case "status":
+		return printStatus(ctx, name, m)
+	default:
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// printStatus 输出迁移状态
+func printStatus(ctx context.Context, name string, m *migrate.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("== %s\n", name)
+	for _, s := range statuses {
+		state := "pending"
+		switch {
+		case s.Missing:
+			state = "applied (file missing)"
+		case s.Modified:
+			state = "applied (modified)"
+		case s.Applied:
+			state = "applied " + s.AppliedAt.Format(time.DateTime)
+		}
+		fmt.Printf("%06d_%-40s %s\n", s.Version, s.Name, state)
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: migrate [-f config] [-db name] [-n steps] up|down|redo|status\n")
+	flag.PrintDefaults()
+}
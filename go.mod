@@ -6,7 +6,21 @@ require (
 	github.com/zeromicro/go-zero v1.9.0
 	gorm.io/gorm v1.25.0
 	gorm.io/driver/mysql v1.5.0
+	github.com/go-sql-driver/mysql v1.9.0
 	github.com/go-playground/validator/v10 v10.15.0
+	gorm.io/driver/sqlite v1.5.0
+	github.com/mattn/go-sqlite3 v1.14.15
+	github.com/segmentio/kafka-go v0.4.50
+	github.com/fsnotify/fsnotify v1.7.0
 	go.opentelemetry.io/otel v1.21.0
 	go.opentelemetry.io/otel/trace v1.21.0
+	go.opentelemetry.io/otel/metric v1.21.0
+	go.opentelemetry.io/otel/sdk/metric v1.24.0
+	github.com/google/uuid v1.6.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.24.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.24.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.24.0
+	go.opentelemetry.io/otel/exporters/zipkin v1.24.0
+	go.opentelemetry.io/contrib/propagators/b3 v1.24.0
+	go.opentelemetry.io/contrib/propagators/jaeger v1.20.0
 )
@@ -0,0 +1 @@
+-- data_understanding 库基线版本，无需回滚
@@ -0,0 +1,2 @@
+-- data_view 库基线版本
+-- 业务表迁移从 000002 开始追加
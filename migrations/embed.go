@@ -0,0 +1,28 @@
+// Package migrations 各数据库的版本化迁移文件
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// 数据库名称
+const (
+	ResourceCatalog   = "resource_catalog"
+	DataView          = "data_view"
+	DataUnderstanding = "data_understanding"
+)
+
+//go:embed resource_catalog/*.sql data_view/*.sql data_understanding/*.sql
+var files embed.FS
+
+// FS 获取指定数据库的迁移文件
+func FS(database string) (fs.FS, error) {
+	switch database {
+	case ResourceCatalog, DataView, DataUnderstanding:
+		return fs.Sub(files, database)
+	default:
+		return nil, fmt.Errorf("unknown migration database: %s", database)
+	}
+}
@@ -0,0 +1,16 @@
+-- 资源目录分类表
+CREATE TABLE IF NOT EXISTS `category` (
+    `id`          BIGINT       NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `parent_id`   BIGINT       NOT NULL DEFAULT 0 COMMENT '父分类ID，0表示根分类',
+    `name`        VARCHAR(100) NOT NULL COMMENT '分类名称',
+    `code`        VARCHAR(50)  NOT NULL COMMENT '分类编码',
+    `sort`        INT          NOT NULL DEFAULT 0 COMMENT '排序',
+    `status`      TINYINT      NOT NULL DEFAULT 1 COMMENT '状态：1启用 0禁用',
+    `description` VARCHAR(500) NOT NULL DEFAULT '' COMMENT '描述',
+    `created_at`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `deleted_at`  DATETIME     NULL COMMENT '删除时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_code` (`code`),
+    KEY `idx_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='资源目录分类';
@@ -0,0 +1,56 @@
+package db
+
+import "fmt"
+
+// Config 数据库配置（GORM 与 SQLx 共用）
+type Config struct {
+	// 连接信息
+	Host     string `json:",default=127.0.0.1"`
+	Port     int    `json:",default=3306"`
+	Database string
+	Username string
+	Password string
+	Charset  string `json:",default=utf8mb4"`
+
+	// 连接池配置
+	MaxIdleConns    int `json:",default=10"`
+	MaxOpenConns    int `json:",default=100"`
+	ConnMaxLifetime int `json:",default=3600"` // 连接最大存活时间(秒)
+	ConnMaxIdleTime int `json:",default=600"`  // 连接最大空闲时间(秒)
+
+	// GORM 配置
+	LogLevel          string `json:",default=warn"` // silent/error/warn/info
+	SlowThreshold     int    `json:",default=200"`  // 慢查询阈值(毫秒)
+	SkipDefaultTxn    bool   `json:",default=true"`
+	PrepareStmt       bool   `json:",default=true"`
+	SingularTable     bool   `json:",default=true"`
+	DisableForeignKey bool   `json:",default=true"`
+
+	// 重试策略
+	Retry RetryConfig
+
+	// 启动时自动执行数据库迁移
+	MigrateOnStartup bool `json:",default=false"`
+}
+
+// RetryConfig 重试配置
+type RetryConfig struct {
+	MaxAttempts    int      `json:",default=3"`    // 最大尝试次数（含首次），1 表示不重试
+	InitialBackoff int      `json:",default=50"`   // 首次退避(毫秒)
+	MaxBackoff     int      `json:",default=1000"` // 最大退避(毫秒)
+	Multiplier     float64  `json:",default=2"`    // 退避倍数
+	Jitter         float64  `json:",default=0.2"`  // 抖动比例 0.0-1.0
+	RetryOn        []string `json:",optional"`     // deadlock/lock_wait_timeout/connection，为空表示全部
+}
+
+// DSN 构建 MySQL 连接串
+func (c Config) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
+		c.Username,
+		c.Password,
+		c.Host,
+		c.Port,
+		c.Database,
+		c.Charset,
+	)
+}
@@ -0,0 +1,121 @@
+// Package dbtest 双ORM一致性测试套件
+//
+// Model 工厂在 GORM 不可用时会静默降级到 SQLx，两套实现必须行为一致。
+// 本包使用进程内的 SQLite 引擎为每个 ORM 创建独立数据库，并对同一个
+// Model 运行相同的表驱动用例（不存在映射、唯一键冲突、分页、事务）。
+package dbtest
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"idrm/pkg/db"
+	"idrm/pkg/errorx"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// 后端名称
+const (
+	BackendGorm = "gorm"
+	BackendSqlx = "sqlx"
+)
+
+// Backend 被测 ORM 后端
+// 与 Model 工厂的约定一致：只有一个连接非空，工厂据此选择实现
+type Backend struct {
+	Name   string
+	SQLDB  *sql.DB
+	GormDB *gorm.DB
+}
+
+// NewBackends 为 GORM 和 SQLx 分别创建独立的数据库并执行建表语句
+// 两个后端分别通过 db.OpenGorm 和 db.OpenConnector 创建，与生产环境使用相同的回调和驱动包装，
+// 仅将方言替换为 SQLite；schema 需使用 SQLite 兼容的 DDL，多条语句以分号分隔
+func NewBackends(t testing.TB, schema string) []Backend {
+	t.Helper()
+
+	return []Backend{
+		{Name: BackendGorm, GormDB: openGorm(t, schema)},
+		{Name: BackendSqlx, SQLDB: openSQL(t, schema)},
+	}
+}
+
+// config 测试后端的连接配置
+func config(name string) db.Config {
+	return db.Config{
+		Database:       name,
+		LogLevel:       "silent",
+		SkipDefaultTxn: true,
+		MaxIdleConns:   2,
+	}
+}
+
+// openGorm 打开 GORM 后端
+func openGorm(t testing.TB, schema string) *gorm.DB {
+	t.Helper()
+
+	conn := sql.OpenDB(&sqliteConnector{dsn: dsn(t, BackendGorm)})
+	t.Cleanup(func() { _ = conn.Close() })
+
+	gormDB, err := db.OpenGorm(&sqlite.Dialector{Conn: conn}, config(BackendGorm))
+	if err != nil {
+		t.Fatalf("open gorm backend: %v", err)
+	}
+
+	execSchema(t, conn, schema)
+	return gormDB
+}
+
+// openSQL 打开 SQLx 后端（database/sql 连接）
+func openSQL(t testing.TB, schema string) *sql.DB {
+	t.Helper()
+
+	conn, err := db.OpenConnector(&sqliteConnector{dsn: dsn(t, BackendSqlx)}, config(BackendSqlx))
+	if err != nil {
+		t.Fatalf("open sqlx backend: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	execSchema(t, conn, schema)
+	return conn
+}
+
+// dsn 构建临时数据库文件地址（测试结束后自动删除）
+func dsn(t testing.TB, name string) string {
+	path := filepath.Join(t.TempDir(), name+".db")
+	return fmt.Sprintf("file:%s?_busy_timeout=5000&_foreign_keys=1", path)
+}
+
+// execSchema 执行建表语句
+func execSchema(t testing.TB, conn *sql.DB, schema string) {
+	t.Helper()
+
+	if schema == "" {
+		return
+	}
+	if _, err := conn.Exec(schema); err != nil {
+		t.Fatalf("exec schema: %v", err)
+	}
+}
+
+// AssertCode 断言错误为指定错误码的 errorx.CodeError
+func AssertCode(t testing.TB, err error, code int) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("expected error code %d, got nil", code)
+	}
+
+	var codeErr *errorx.CodeError
+	if !errors.As(err, &codeErr) {
+		t.Fatalf("expected error code %d, got non-CodeError: %v", code, err)
+	}
+	if codeErr.GetCode() != code {
+		t.Fatalf("expected error code %d, got %d: %v", code, codeErr.GetCode(), err)
+	}
+}
@@ -0,0 +1,84 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// sqliteConnector SQLite 驱动连接器，作为 db.OpenGorm / db.OpenConnector 的底层连接
+// 生产环境的 MySQL 驱动返回带错误号的 *mysql.MySQLError，db.Translate 据此识别唯一键冲突；
+// 这里按 SQLite 的扩展错误码将唯一约束冲突标记为 gorm.ErrDuplicatedKey，生产代码无需识别 SQLite 错误
+type sqliteConnector struct {
+	dsn string
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	base, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{SQLiteConn: base.(*sqlite3.SQLiteConn)}, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+// sqliteConn 转换唯一约束错误的 SQLite 连接
+// 未覆盖的方法（Prepare、Begin 等）由内嵌的 *sqlite3.SQLiteConn 提供
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res, err := c.SQLiteConn.ExecContext(ctx, query, args)
+	return res, duplicateKey(err)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.SQLiteConn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, duplicateKey(err)
+	}
+	return &sqliteRows{Rows: rows}, nil
+}
+
+// sqliteRows INSERT ... RETURNING 的约束错误在读取结果时返回
+type sqliteRows struct {
+	driver.Rows
+}
+
+func (r *sqliteRows) Next(dest []driver.Value) error {
+	return duplicateKey(r.Rows.Next(dest))
+}
+
+// duplicateKeyError 唯一约束冲突，errors.Is(err, gorm.ErrDuplicatedKey) 成立
+type duplicateKeyError struct {
+	err error
+}
+
+func (e *duplicateKeyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *duplicateKeyError) Unwrap() error {
+	return e.err
+}
+
+func (e *duplicateKeyError) Is(target error) bool {
+	return target == gorm.ErrDuplicatedKey
+}
+
+// duplicateKey 将 SQLite 唯一约束和主键冲突转换为 duplicateKeyError
+func duplicateKey(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey) {
+		return &duplicateKeyError{err: err}
+	}
+	return err
+}
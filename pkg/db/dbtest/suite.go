@@ -0,0 +1,202 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"idrm/pkg/errorx"
+
+	"gorm.io/gorm"
+)
+
+// errRollback 事务回滚用例使用的哨兵错误
+var errRollback = errors.New("dbtest: rollback")
+
+// Suite 双ORM一致性测试套件
+// M 为 Model 接口类型，T 为数据结构类型；可选操作为 nil 时跳过对应用例
+type Suite[M any, T any] struct {
+	// Schema SQLite 兼容的建表语句
+	Schema string
+
+	// NewModel Model 工厂（即 model 包的 NewModel）
+	NewModel func(sqlConn *sql.DB, gormDB *gorm.DB) M
+
+	// NewData 生成第 seq 条测试数据，相同 seq 生成的数据唯一键相同
+	NewData func(seq int) *T
+
+	// GetID 获取数据主键
+	GetID func(data *T) int64
+
+	// 必选操作
+	Insert  func(ctx context.Context, m M, data *T) (*T, error)
+	FindOne func(ctx context.Context, m M, id int64) (*T, error)
+
+	// 可选操作
+	Delete   func(ctx context.Context, m M, id int64) error
+	FindPage func(ctx context.Context, m M, page, pageSize int) ([]*T, int64, error)
+	Trans    func(ctx context.Context, m M, fn func(ctx context.Context, tx M) error) error
+}
+
+// testCase 一致性用例
+type testCase[M any, T any] struct {
+	name string
+	skip bool
+	run  func(t *testing.T, ctx context.Context, m M)
+}
+
+// Run 在 GORM 和 SQLx 两个后端上运行全部用例
+func (s Suite[M, T]) Run(t *testing.T) {
+	t.Helper()
+
+	for _, tc := range s.cases() {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.skip {
+				t.Skip("operation not provided")
+			}
+			for _, b := range NewBackends(t, s.Schema) {
+				b := b
+				t.Run(b.Name, func(t *testing.T) {
+					tc.run(t, context.Background(), s.NewModel(b.SQLDB, b.GormDB))
+				})
+			}
+		})
+	}
+}
+
+// cases 用例列表
+func (s Suite[M, T]) cases() []testCase[M, T] {
+	return []testCase[M, T]{
+		{name: "insert_and_find", run: s.testInsertAndFind},
+		{name: "not_found", run: s.testNotFound},
+		{name: "duplicate_key", run: s.testDuplicateKey},
+		{name: "delete", skip: s.Delete == nil, run: s.testDelete},
+		{name: "pagination", skip: s.FindPage == nil, run: s.testPagination},
+		{name: "trans_commit", skip: s.Trans == nil, run: s.testTransCommit},
+		{name: "trans_rollback", skip: s.Trans == nil, run: s.testTransRollback},
+	}
+}
+
+// mustInsert 插入数据，失败时终止用例
+func (s Suite[M, T]) mustInsert(t *testing.T, ctx context.Context, m M, seq int) *T {
+	t.Helper()
+
+	data, err := s.Insert(ctx, m, s.NewData(seq))
+	if err != nil {
+		t.Fatalf("insert seq %d: %v", seq, err)
+	}
+	return data
+}
+
+func (s Suite[M, T]) testInsertAndFind(t *testing.T, ctx context.Context, m M) {
+	inserted := s.mustInsert(t, ctx, m, 1)
+
+	id := s.GetID(inserted)
+	if id <= 0 {
+		t.Fatalf("insert returned invalid id %d", id)
+	}
+
+	found, err := s.FindOne(ctx, m, id)
+	if err != nil {
+		t.Fatalf("find inserted: %v", err)
+	}
+	if got := s.GetID(found); got != id {
+		t.Fatalf("find returned id %d, want %d", got, id)
+	}
+}
+
+func (s Suite[M, T]) testNotFound(t *testing.T, ctx context.Context, m M) {
+	_, err := s.FindOne(ctx, m, 1<<40)
+	AssertCode(t, err, errorx.ErrCodeNotFound)
+}
+
+func (s Suite[M, T]) testDuplicateKey(t *testing.T, ctx context.Context, m M) {
+	s.mustInsert(t, ctx, m, 1)
+
+	_, err := s.Insert(ctx, m, s.NewData(1))
+	AssertCode(t, err, errorx.ErrCodeAlreadyExists)
+}
+
+func (s Suite[M, T]) testDelete(t *testing.T, ctx context.Context, m M) {
+	id := s.GetID(s.mustInsert(t, ctx, m, 1))
+
+	if err := s.Delete(ctx, m, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	_, err := s.FindOne(ctx, m, id)
+	AssertCode(t, err, errorx.ErrCodeNotFound)
+}
+
+func (s Suite[M, T]) testPagination(t *testing.T, ctx context.Context, m M) {
+	for seq := 1; seq <= 5; seq++ {
+		s.mustInsert(t, ctx, m, seq)
+	}
+
+	tests := []struct {
+		page     int
+		pageSize int
+		wantLen  int
+	}{
+		{page: 1, pageSize: 2, wantLen: 2},
+		{page: 3, pageSize: 2, wantLen: 1},
+		{page: 4, pageSize: 2, wantLen: 0},
+		{page: 1, pageSize: 10, wantLen: 5},
+	}
+
+	for _, tt := range tests {
+		list, total, err := s.FindPage(ctx, m, tt.page, tt.pageSize)
+		if err != nil {
+			t.Fatalf("find page %d/%d: %v", tt.page, tt.pageSize, err)
+		}
+		if total != 5 {
+			t.Errorf("page %d/%d total = %d, want 5", tt.page, tt.pageSize, total)
+		}
+		if len(list) != tt.wantLen {
+			t.Errorf("page %d/%d len = %d, want %d", tt.page, tt.pageSize, len(list), tt.wantLen)
+		}
+	}
+}
+
+func (s Suite[M, T]) testTransCommit(t *testing.T, ctx context.Context, m M) {
+	var ids []int64
+	err := s.Trans(ctx, m, func(ctx context.Context, tx M) error {
+		for seq := 1; seq <= 2; seq++ {
+			data, err := s.Insert(ctx, tx, s.NewData(seq))
+			if err != nil {
+				return err
+			}
+			ids = append(ids, s.GetID(data))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("trans: %v", err)
+	}
+
+	for _, id := range ids {
+		if _, err := s.FindOne(ctx, m, id); err != nil {
+			t.Errorf("find committed id %d: %v", id, err)
+		}
+	}
+}
+
+func (s Suite[M, T]) testTransRollback(t *testing.T, ctx context.Context, m M) {
+	var id int64
+	err := s.Trans(ctx, m, func(ctx context.Context, tx M) error {
+		data, err := s.Insert(ctx, tx, s.NewData(1))
+		if err != nil {
+			return err
+		}
+		id = s.GetID(data)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("trans error = %v, want %v", err, errRollback)
+	}
+
+	_, err = s.FindOne(ctx, m, id)
+	AssertCode(t, err, errorx.ErrCodeNotFound)
+}
@@ -0,0 +1,165 @@
+package dbtest_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"idrm/pkg/db"
+	"idrm/pkg/db/dbtest"
+
+	"gorm.io/gorm"
+)
+
+const itemSchema = `CREATE TABLE item (
+	id   INTEGER PRIMARY KEY AUTOINCREMENT,
+	code TEXT NOT NULL UNIQUE
+);`
+
+type item struct {
+	Id   int64
+	Code string
+}
+
+func (item) TableName() string { return "item" }
+
+// itemModel 测试用的双ORM Model，覆盖 Suite 的全部操作
+// （仓库中尚无 model/ 目录下的业务 Model，新增时按同样方式接入 Suite）
+// 方法直接返回 ORM 错误，错误码由 GORM 回调和驱动层自动转换，db.QueryRow 负责 sql.ErrNoRows
+type itemModel interface {
+	Insert(ctx context.Context, data *item) (*item, error)
+	FindOne(ctx context.Context, id int64) (*item, error)
+	Delete(ctx context.Context, id int64) error
+	FindPage(ctx context.Context, page, pageSize int) ([]*item, int64, error)
+	Trans(ctx context.Context, fn func(ctx context.Context, tx itemModel) error) error
+}
+
+func newItemModel(sqlConn *sql.DB, gormDB *gorm.DB) itemModel {
+	if gormDB != nil {
+		return &gormItem{db: gormDB}
+	}
+	return &sqlItem{db: sqlConn, conn: sqlConn}
+}
+
+type gormItem struct{ db *gorm.DB }
+
+func (m *gormItem) Insert(ctx context.Context, data *item) (*item, error) {
+	if err := m.db.WithContext(ctx).Create(data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (m *gormItem) FindOne(ctx context.Context, id int64) (*item, error) {
+	var data item
+	if err := m.db.WithContext(ctx).First(&data, id).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (m *gormItem) Delete(ctx context.Context, id int64) error {
+	return m.db.WithContext(ctx).Delete(&item{}, id).Error
+}
+
+func (m *gormItem) FindPage(ctx context.Context, page, pageSize int) ([]*item, int64, error) {
+	var (
+		list  []*item
+		total int64
+	)
+	query := m.db.WithContext(ctx).Model(&item{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (m *gormItem) Trans(ctx context.Context, fn func(ctx context.Context, tx itemModel) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ctx, &gormItem{db: tx})
+	})
+}
+
+type sqlItem struct {
+	db   *sql.DB
+	conn db.Querier
+}
+
+func (m *sqlItem) Insert(ctx context.Context, data *item) (*item, error) {
+	res, err := m.conn.ExecContext(ctx, "INSERT INTO item (code) VALUES (?)", data.Code)
+	if err != nil {
+		return nil, err
+	}
+	data.Id, _ = res.LastInsertId()
+	return data, nil
+}
+
+func (m *sqlItem) FindOne(ctx context.Context, id int64) (*item, error) {
+	var data item
+	if err := db.QueryRow(ctx, m.conn, "SELECT id, code FROM item WHERE id = ?", id).Scan(&data.Id, &data.Code); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (m *sqlItem) Delete(ctx context.Context, id int64) error {
+	_, err := m.conn.ExecContext(ctx, "DELETE FROM item WHERE id = ?", id)
+	return err
+}
+
+func (m *sqlItem) FindPage(ctx context.Context, page, pageSize int) ([]*item, int64, error) {
+	var total int64
+	if err := db.QueryRow(ctx, m.conn, "SELECT COUNT(*) FROM item").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := m.conn.QueryContext(ctx, "SELECT id, code FROM item ORDER BY id LIMIT ? OFFSET ?",
+		pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var list []*item
+	for rows.Next() {
+		var data item
+		if err := rows.Scan(&data.Id, &data.Code); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, &data)
+	}
+	return list, total, rows.Err()
+}
+
+func (m *sqlItem) Trans(ctx context.Context, fn func(ctx context.Context, tx itemModel) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(ctx, &sqlItem{db: m.db, conn: tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func TestSuite(t *testing.T) {
+	dbtest.Suite[itemModel, item]{
+		Schema:   itemSchema,
+		NewModel: newItemModel,
+		NewData:  func(seq int) *item { return &item{Code: "C" + string(rune('0'+seq))} },
+		GetID:    func(data *item) int64 { return data.Id },
+		Insert:   func(ctx context.Context, m itemModel, data *item) (*item, error) { return m.Insert(ctx, data) },
+		FindOne:  func(ctx context.Context, m itemModel, id int64) (*item, error) { return m.FindOne(ctx, id) },
+		Delete:   func(ctx context.Context, m itemModel, id int64) error { return m.Delete(ctx, id) },
+		FindPage: func(ctx context.Context, m itemModel, page, pageSize int) ([]*item, int64, error) {
+			return m.FindPage(ctx, page, pageSize)
+		},
+		Trans: func(ctx context.Context, m itemModel, fn func(ctx context.Context, tx itemModel) error) error {
+			return m.Trans(ctx, fn)
+		},
+	}.Run(t)
+}
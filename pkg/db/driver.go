@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+// wrapConnector 包装驱动连接器，在驱动层统一转换错误、追踪查询并重试事务外的查询
+func wrapConnector(c driver.Connector, q *queryTracer, r *Retrier) driver.Connector {
+	return &connector{base: c, tracer: q, retrier: r}
+}
+
+// connector 包装后的连接器
+type connector struct {
+	base    driver.Connector
+	tracer  *queryTracer
+	retrier *Retrier
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return &conn{base: dc, tracer: c.tracer, retrier: c.retrier}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// conn 包装后的连接（database/sql 保证同一连接不会被并发使用）
+type conn struct {
+	base    driver.Conn
+	tracer  *queryTracer
+	retrier *Retrier
+	inTx    bool // 事务中的查询不单独重试
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		st  driver.Stmt
+		err error
+	)
+	if p, ok := c.base.(driver.ConnPrepareContext); ok {
+		st, err = p.PrepareContext(ctx, query)
+	} else {
+		st, err = c.base.Prepare(query)
+	}
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return &stmt{base: st, query: query, conn: c}, nil
+}
+
+func (c *conn) Close() error {
+	return c.base.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var (
+		t   driver.Tx
+		err error
+	)
+	if b, ok := c.base.(driver.ConnBeginTx); ok {
+		t, err = b.BeginTx(ctx, opts)
+	} else {
+		t, err = c.base.Begin() //nolint:staticcheck // 驱动不支持 BeginTx 时的兜底
+	}
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	c.inTx = true
+	return &tx{base: t, conn: c}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.base.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span, start := c.tracer.start(ctx, "exec")
+	res, err := e.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		// 驱动要求预编译执行（带参数且未开启客户端插值）
+		res, err = c.execPrepared(ctx, query, args)
+	}
+	c.tracer.end(ctx, span, start, query, rowsAffected(res), err)
+	return res, wrapErr(err)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.base.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	var rows driver.Rows
+	err := c.read(ctx, func(ctx context.Context) error {
+		ctx, span, start := c.tracer.start(ctx, "query")
+		var err error
+		rows, err = q.QueryContext(ctx, query, args)
+		if errors.Is(err, driver.ErrSkip) {
+			rows, err = c.queryPrepared(ctx, query, args)
+		}
+		c.tracer.end(ctx, span, start, query, -1, err)
+		return err
+	})
+	return rows, wrapErr(err)
+}
+
+// read 执行查询，事务外遇到死锁、锁等待超时按重试策略在当前连接上重试
+// 连接中断不在当前连接上重试：原样返回 driver.ErrBadConn，由 database/sql 换连接重试
+func (c *conn) read(ctx context.Context, fn func(ctx context.Context) error) error {
+	if c.inTx || c.retrier == nil {
+		return fn(ctx)
+	}
+	return c.retrier.retry(ctx, "read", fn, func(reason string) bool {
+		return reason == RetryOnConnection
+	})
+}
+
+// execPrepared 预编译后执行，执行完毕关闭语句
+func (c *conn) execPrepared(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	st, err := c.prepareBase(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+	return stmtExec(ctx, st, args)
+}
+
+// queryPrepared 预编译后查询，结果集关闭时关闭语句
+func (c *conn) queryPrepared(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	st, err := c.prepareBase(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmtQuery(ctx, st, args)
+	if err != nil {
+		_ = st.Close()
+		return nil, err
+	}
+	return &stmtRows{Rows: rows, stmt: st}, nil
+}
+
+// prepareBase 使用底层连接预编译语句
+func (c *conn) prepareBase(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.base.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.base.Prepare(query)
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.base.(driver.Pinger); ok {
+		return wrapErr(p.Ping(ctx))
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.base.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.base.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.base.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt 包装后的预编译语句
+type stmt struct {
+	base  driver.Stmt
+	query string
+	conn  *conn
+}
+
+func (s *stmt) Close() error {
+	return s.base.Close()
+}
+
+func (s *stmt) NumInput() int {
+	return s.base.NumInput()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := s.base.Exec(args) //nolint:staticcheck // 实现 driver.Stmt 接口
+	return res, wrapErr(err)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.base.Query(args) //nolint:staticcheck // 实现 driver.Stmt 接口
+	return rows, wrapErr(err)
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span, start := s.conn.tracer.start(ctx, "exec")
+	res, err := stmtExec(ctx, s.base, args)
+	s.conn.tracer.end(ctx, span, start, s.query, rowsAffected(res), err)
+	return res, wrapErr(err)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	err := s.conn.read(ctx, func(ctx context.Context) error {
+		ctx, span, start := s.conn.tracer.start(ctx, "query")
+		var err error
+		rows, err = stmtQuery(ctx, s.base, args)
+		s.conn.tracer.end(ctx, span, start, s.query, -1, err)
+		return err
+	})
+	return rows, wrapErr(err)
+}
+
+// stmtRows 结果集关闭时同时关闭内部预编译语句
+type stmtRows struct {
+	driver.Rows
+	stmt driver.Stmt
+}
+
+func (r *stmtRows) Close() error {
+	err := r.Rows.Close()
+	if stErr := r.stmt.Close(); err == nil {
+		err = stErr
+	}
+	return err
+}
+
+// tx 包装后的事务
+type tx struct {
+	base driver.Tx
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	t.conn.inTx = false
+	return wrapErr(t.base.Commit())
+}
+
+func (t *tx) Rollback() error {
+	t.conn.inTx = false
+	return wrapErr(t.base.Rollback())
+}
+
+// stmtExec 执行预编译语句
+func stmtExec(ctx context.Context, st driver.Stmt, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := st.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+	return st.Exec(namedToValues(args)) //nolint:staticcheck // 驱动不支持 context 时的兜底
+}
+
+// stmtQuery 查询预编译语句
+func stmtQuery(ctx context.Context, st driver.Stmt, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := st.(driver.StmtQueryContext); ok {
+		return q.QueryContext(ctx, args)
+	}
+	return st.Query(namedToValues(args)) //nolint:staticcheck // 驱动不支持 context 时的兜底
+}
+
+// rowsAffected 获取影响行数，未知时返回 -1
+func rowsAffected(res driver.Result) int64 {
+	if res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// namedToValues 将 NamedValue 转换为 Value
+func namedToValues(named []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(named))
+	for i, nv := range named {
+		values[i] = nv.Value
+	}
+	return values
+}
+
+// wrapErr 转换驱动错误
+// database/sql 依赖的控制错误（ErrSkip、ErrBadConn 等）原样返回
+func wrapErr(err error) error {
+	if err == nil ||
+		errors.Is(err, driver.ErrSkip) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, driver.ErrRemoveArgument) {
+		return err
+	}
+	return Translate(err)
+}
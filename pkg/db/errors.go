@@ -0,0 +1,113 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"syscall"
+
+	"idrm/pkg/errorx"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// MySQL 错误号
+const (
+	mysqlErrDupEntry        = 1062 // Duplicate entry
+	mysqlErrLockWaitTimeout = 1205 // Lock wait timeout exceeded
+	mysqlErrDeadlock        = 1213 // Deadlock found when trying to get lock
+)
+
+// retryableError 标记可重试的数据库错误（死锁、锁等待超时、连接中断）
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Translate 将数据库驱动错误转换为 errorx 错误码
+// 原始错误通过错误链保留，可继续使用 errors.Is(err, gorm.ErrRecordNotFound) 等判断
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := errorx.FromError(err); ok {
+		return err // 已转换
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, gorm.ErrRecordNotFound):
+		return errorx.Wrap(errorx.ErrCodeNotFound, err)
+	case isDuplicateKey(err):
+		return errorx.Wrap(errorx.ErrCodeAlreadyExists, err)
+	case isRetryableCause(err):
+		return errorx.Wrap(errorx.ErrCodeDatabase, &retryableError{err: err})
+	default:
+		return errorx.Wrap(errorx.ErrCodeDatabase, err)
+	}
+}
+
+// IsRetryable 判断错误是否可重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var re *retryableError
+	if errors.As(err, &re) {
+		return true
+	}
+	return isRetryableCause(err)
+}
+
+// isDuplicateKey 是否唯一键冲突
+func isDuplicateKey(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == mysqlErrDupEntry
+}
+
+// 可重试错误类型（RetryConfig.RetryOn 取值）
+const (
+	RetryOnDeadlock        = "deadlock"
+	RetryOnLockWaitTimeout = "lock_wait_timeout"
+	RetryOnConnection      = "connection"
+)
+
+// isRetryableCause 是否为可重试的原始错误
+func isRetryableCause(err error) bool {
+	return retryReason(err) != ""
+}
+
+// retryReason 返回可重试错误的类型，不可重试时返回空串
+func retryReason(err error) string {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case mysqlErrDeadlock:
+			return RetryOnDeadlock
+		case mysqlErrLockWaitTimeout:
+			return RetryOnLockWaitTimeout
+		}
+		return ""
+	}
+
+	// 连接中断（主从切换、网络抖动）
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return RetryOnConnection
+	}
+	return ""
+}
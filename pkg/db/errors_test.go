@@ -0,0 +1,93 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"idrm/pkg/errorx"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      int
+		wantRetryable bool
+	}{
+		{
+			name:     "sql无数据",
+			err:      sql.ErrNoRows,
+			wantCode: errorx.ErrCodeNotFound,
+		},
+		{
+			name:     "gorm无数据",
+			err:      fmt.Errorf("find category: %w", gorm.ErrRecordNotFound),
+			wantCode: errorx.ErrCodeNotFound,
+		},
+		{
+			name:     "唯一键冲突",
+			err:      &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'C001' for key 'uk_code'"},
+			wantCode: errorx.ErrCodeAlreadyExists,
+		},
+		{
+			name:          "死锁",
+			err:           &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"},
+			wantCode:      errorx.ErrCodeDatabase,
+			wantRetryable: true,
+		},
+		{
+			name:          "锁等待超时",
+			err:           &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"},
+			wantCode:      errorx.ErrCodeDatabase,
+			wantRetryable: true,
+		},
+		{
+			name:          "连接中断",
+			err:           driver.ErrBadConn,
+			wantCode:      errorx.ErrCodeDatabase,
+			wantRetryable: true,
+		},
+		{
+			name:     "其他错误",
+			err:      &mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax"},
+			wantCode: errorx.ErrCodeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Translate(tt.err)
+
+			codeErr, ok := errorx.FromError(err)
+			if !ok {
+				t.Fatalf("Translate() = %v, want CodeError", err)
+			}
+			if codeErr.GetCode() != tt.wantCode {
+				t.Errorf("Translate() code = %d, want %d", codeErr.GetCode(), tt.wantCode)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Translate() lost original error %v", tt.err)
+			}
+			if got := IsRetryable(err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestTranslate_Idempotent(t *testing.T) {
+	if Translate(nil) != nil {
+		t.Fatal("Translate(nil) should be nil")
+	}
+
+	first := Translate(sql.ErrNoRows)
+	if second := Translate(first); second != first {
+		t.Errorf("Translate() should not re-wrap CodeError")
+	}
+}
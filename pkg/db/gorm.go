@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// callbackTranslateError 错误转换回调名称
+const callbackTranslateError = "idrm:translate_error"
+
+// InitGorm 初始化 GORM 连接
+func InitGorm(cfg Config) (*gorm.DB, error) {
+	return OpenGorm(mysql.Open(cfg.DSN()), cfg)
+}
+
+// OpenGorm 使用指定方言打开 GORM 连接，注册与 InitGorm 相同的重试、错误转换和追踪回调
+// 一致性测试通过它以 SQLite 方言运行生产配置
+func OpenGorm(dialector gorm.Dialector, cfg Config) (*gorm.DB, error) {
+	gormDB, err := gorm.Open(dialector, &gorm.Config{
+		Logger:                                   newGormLogger(cfg),
+		SkipDefaultTransaction:                   cfg.SkipDefaultTxn,
+		PrepareStmt:                              cfg.PrepareStmt,
+		DisableForeignKeyConstraintWhenMigrating: cfg.DisableForeignKey,
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: cfg.SingularTable,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open gorm: %w", err)
+	}
+
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get gorm sql.DB: %w", err)
+	}
+	setPool(sqlDB, cfg)
+
+	if err := registerRetryCallbacks(gormDB, NewRetrier(cfg.Retry)); err != nil {
+		return nil, fmt.Errorf("register gorm retry callbacks: %w", err)
+	}
+	if err := registerErrorCallbacks(gormDB); err != nil {
+		return nil, fmt.Errorf("register gorm callbacks: %w", err)
+	}
+	if err := registerTraceCallbacks(gormDB, newQueryTracer(cfg)); err != nil {
+		return nil, fmt.Errorf("register gorm trace callbacks: %w", err)
+	}
+
+	return gormDB, nil
+}
+
+// newGormLogger 创建 GORM 日志器
+// 慢查询由追踪回调统一输出（带 TraceID/RequestID），此处不再重复记录
+func newGormLogger(cfg Config) logger.Interface {
+	return logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+		LogLevel:                  parseLogLevel(cfg.LogLevel),
+		IgnoreRecordNotFoundError: true,
+	})
+}
+
+// parseLogLevel 解析 GORM 日志级别
+func parseLogLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+// registerRetryCallbacks 替换查询回调，事务外的查询遇到可重试错误时按重试策略重新执行
+// 事务中的查询不单独重试，由 Retrier.Trans 整体重试事务
+func registerRetryCallbacks(gormDB *gorm.DB, r *Retrier) error {
+	processor := gormDB.Callback().Query()
+	query := processor.Get("gorm:query")
+	if query == nil {
+		return errors.New("gorm:query callback not registered")
+	}
+
+	return processor.Replace("gorm:query", func(tx *gorm.DB) {
+		if tx.Error != nil || inTransaction(tx) {
+			query(tx)
+			return
+		}
+		_ = r.retry(tx.Statement.Context, "read", func(ctx context.Context) error {
+			tx.Error = nil
+			query(tx)
+			return tx.Error
+		}, nil)
+	})
+}
+
+// inTransaction 当前语句是否在事务中执行
+func inTransaction(tx *gorm.DB) bool {
+	_, ok := tx.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+// registerErrorCallbacks 注册错误转换回调
+// 在每个操作链末尾将 tx.Error 转换为 errorx 错误码
+func registerErrorCallbacks(gormDB *gorm.DB) error {
+	cb := gormDB.Callback()
+	translate := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			tx.Error = Translate(tx.Error)
+		}
+	}
+
+	registers := []func(name string, fn func(*gorm.DB)) error{
+		cb.Create().After("*").Register,
+		cb.Query().After("*").Register,
+		cb.Update().After("*").Register,
+		cb.Delete().After("*").Register,
+		cb.Row().After("*").Register,
+		cb.Raw().After("*").Register,
+	}
+	for _, register := range registers {
+		if err := register(callbackTranslateError, translate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
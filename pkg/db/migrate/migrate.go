@@ -0,0 +1,302 @@
+// Package migrate 版本化数据库迁移
+//
+// 迁移文件按 {version}_{name}.up.sql / .down.sql 命名并嵌入二进制，
+// 已执行的版本及其校验和记录在 schema_migrations 表中，
+// 执行期间通过 MySQL GET_LOCK 加咨询锁，避免多实例并发迁移。
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// migrationsTable 迁移记录表
+	migrationsTable = "schema_migrations"
+
+	// lockTimeout 获取咨询锁的等待时间(秒)
+	lockTimeout = 60
+)
+
+var (
+	// ErrLocked 其他实例正在执行迁移
+	ErrLocked = errors.New("migrate: another instance holds the migration lock")
+
+	// ErrChecksumMismatch 已执行的迁移文件被修改
+	ErrChecksumMismatch = errors.New("migrate: applied migration file was modified")
+
+	// ErrMissingFile 已执行的迁移找不到对应文件
+	ErrMissingFile = errors.New("migrate: applied migration file is missing")
+)
+
+// Status 迁移状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // 文件在执行后被修改
+	Missing   bool // 已执行但文件不存在
+}
+
+// record 迁移记录
+type record struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *sql.DB
+	database   string
+	migrations []Migration
+}
+
+// New 创建迁移执行器，database 用于咨询锁命名
+func New(db *sql.DB, database string, source fs.FS) (*Migrator, error) {
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, database: database, migrations: migrations}, nil
+}
+
+// Up 执行全部未执行的迁移，返回执行数量
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, records map[int64]record) error {
+		for _, mig := range m.migrations {
+			if _, ok := records[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 回滚最近 steps 个迁移，返回回滚数量
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, records map[int64]record) error {
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := records[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Redo 回滚并重新执行最近一个迁移
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn, records map[int64]record) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := records[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			return m.apply(ctx, conn, mig)
+		}
+		return nil
+	})
+}
+
+// Status 查询迁移状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get conn: %w", err)
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	records, err := loadRecords(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	known := make(map[int64]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if r, ok := records[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.AppliedAt
+			s.Modified = r.Checksum != mig.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+	for _, r := range records {
+		if !known[r.Version] {
+			statuses = append(statuses, Status{
+				Version: r.Version, Name: r.Name, Applied: true, AppliedAt: r.AppliedAt, Missing: true,
+			})
+		}
+	}
+	return statuses, nil
+}
+
+// withLock 在咨询锁保护下执行 fn，执行前校验已执行迁移的完整性
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, records map[int64]record) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get conn: %w", err)
+	}
+	defer conn.Close()
+
+	lockName := "idrm_migrate_" + m.database
+	if err := acquireLock(ctx, conn, lockName); err != nil {
+		return err
+	}
+	defer releaseLock(conn, lockName)
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	records, err := loadRecords(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(records); err != nil {
+		return err
+	}
+	return fn(conn, records)
+}
+
+// verify 校验已执行迁移的文件存在且未被修改
+func (m *Migrator) verify(records map[int64]record) error {
+	files := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		files[mig.Version] = mig
+	}
+
+	for version, r := range records {
+		mig, ok := files[version]
+		if !ok {
+			return fmt.Errorf("%w: %d_%s", ErrMissingFile, version, r.Name)
+		}
+		if mig.Checksum != r.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, mig.Name)
+		}
+	}
+	return nil
+}
+
+// apply 执行单个迁移并记录
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	logx.Infof("[migrate] %s: up %d_%s", m.database, mig.Version, mig.Name)
+
+	if err := execScript(ctx, conn, mig.Up); err != nil {
+		return fmt.Errorf("up %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	_, err := conn.ExecContext(ctx,
+		"INSERT INTO "+migrationsTable+" (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+		mig.Version, mig.Name, mig.Checksum, time.Now())
+	if err != nil {
+		return fmt.Errorf("record %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+// revert 回滚单个迁移并删除记录
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	logx.Infof("[migrate] %s: down %d_%s", m.database, mig.Version, mig.Name)
+
+	if err := execScript(ctx, conn, mig.Down); err != nil {
+		return fmt.Errorf("down %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	_, err := conn.ExecContext(ctx, "DELETE FROM "+migrationsTable+" WHERE version = ?", mig.Version)
+	if err != nil {
+		return fmt.Errorf("delete record %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+// execScript 逐条执行迁移脚本
+func execScript(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTable 创建迁移记录表
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+migrationsTable+` (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum CHAR(64) NOT NULL,
+	applied_at DATETIME NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", migrationsTable, err)
+	}
+	return nil
+}
+
+// loadRecords 加载已执行的迁移记录
+func loadRecords(ctx context.Context, conn *sql.Conn) (map[int64]record, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM "+migrationsTable)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", migrationsTable, err)
+	}
+	defer rows.Close()
+
+	records := make(map[int64]record)
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.Version, &r.Name, &r.Checksum, &r.AppliedAt); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", migrationsTable, err)
+		}
+		records[r.Version] = r
+	}
+	return records, rows.Err()
+}
+
+// acquireLock 获取 MySQL 咨询锁
+func acquireLock(ctx context.Context, conn *sql.Conn, name string) error {
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, lockTimeout).Scan(&got); err != nil {
+		return fmt.Errorf("get lock: %w", err)
+	}
+	if !got.Valid || got.Int64 != 1 {
+		return ErrLocked
+	}
+	return nil
+}
+
+// releaseLock 释放 MySQL 咨询锁
+func releaseLock(conn *sql.Conn, name string) {
+	if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name); err != nil {
+		logx.Errorf("[migrate] release lock %s failed: %v", name, err)
+	}
+}
@@ -0,0 +1,266 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// testDriver 注册了 GET_LOCK / RELEASE_LOCK 的 SQLite 驱动，咨询锁记录在 heldLocks 中
+const testDriver = "sqlite3_migrate"
+
+var (
+	registerOnce sync.Once
+	locksMu      sync.Mutex
+	heldLocks    = map[string]bool{}
+)
+
+// openTestDB 打开临时 SQLite 数据库
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	registerOnce.Do(func() {
+		sql.Register(testDriver, &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				if err := conn.RegisterFunc("GET_LOCK", getLock, false); err != nil {
+					return err
+				}
+				return conn.RegisterFunc("RELEASE_LOCK", releaseLockFunc, false)
+			},
+		})
+	})
+
+	db, err := sql.Open(testDriver, "file:"+filepath.Join(t.TempDir(), "migrate.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// getLock 模拟 MySQL GET_LOCK：锁已被持有时返回 0
+func getLock(name string, timeout int64) int64 {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+	if heldLocks[name] {
+		return 0
+	}
+	heldLocks[name] = true
+	return 1
+}
+
+// releaseLockFunc 模拟 MySQL RELEASE_LOCK
+func releaseLockFunc(name string) int64 {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+	delete(heldLocks, name)
+	return 1
+}
+
+// lockHeld 锁是否被持有
+func lockHeld(name string) bool {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+	return heldLocks[name]
+}
+
+// testSource 两个迁移：建表和追加列
+func testSource() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_create_category.up.sql":   {Data: []byte("CREATE TABLE category (id BIGINT);")},
+		"000001_create_category.down.sql": {Data: []byte("DROP TABLE category;")},
+		"000002_create_tag.up.sql":        {Data: []byte("CREATE TABLE tag (id BIGINT);\nINSERT INTO tag VALUES (1);")},
+		"000002_create_tag.down.sql":      {Data: []byte("DROP TABLE tag;")},
+	}
+}
+
+// newTestMigrator 创建迁移执行器
+func newTestMigrator(t *testing.T, db *sql.DB, source fstest.MapFS) *Migrator {
+	t.Helper()
+
+	m, err := New(db, t.Name(), source)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m
+}
+
+// tableExists 表是否存在
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n); err != nil {
+		t.Fatalf("query sqlite_master: %v", err)
+	}
+	return n > 0
+}
+
+// appliedVersions 已执行的迁移版本数
+func appliedVersions(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + migrationsTable).Scan(&n); err != nil {
+		t.Fatalf("query %s: %v", migrationsTable, err)
+	}
+	return n
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := newTestMigrator(t, db, testSource())
+
+	if n, err := m.Up(ctx); err != nil || n != 2 {
+		t.Fatalf("Up() = %d, %v, want 2", n, err)
+	}
+	if !tableExists(t, db, "category") || !tableExists(t, db, "tag") || appliedVersions(t, db) != 2 {
+		t.Fatal("Up() did not create tables and records")
+	}
+	if lockHeld("idrm_migrate_" + t.Name()) {
+		t.Error("lock not released after Up()")
+	}
+
+	// 再次执行不重复迁移
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("second Up() = %d, %v, want 0", n, err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil || len(statuses) != 2 || !statuses[0].Applied || !statuses[1].Applied || statuses[1].AppliedAt.IsZero() {
+		t.Fatalf("Status() = %+v, %v", statuses, err)
+	}
+
+	// 回滚最近一个
+	if n, err := m.Down(ctx, 1); err != nil || n != 1 {
+		t.Fatalf("Down(1) = %d, %v, want 1", n, err)
+	}
+	if !tableExists(t, db, "category") || tableExists(t, db, "tag") || appliedVersions(t, db) != 1 {
+		t.Fatal("Down(1) did not revert the latest migration only")
+	}
+
+	// 重做最近一个已执行的迁移
+	if err := m.Redo(ctx); err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	if !tableExists(t, db, "category") || tableExists(t, db, "tag") || appliedVersions(t, db) != 1 {
+		t.Fatal("Redo() changed migrations other than the latest applied one")
+	}
+
+	// 回滚数量超过已执行数量时全部回滚
+	if n, err := m.Down(ctx, 5); err != nil || n != 1 {
+		t.Fatalf("Down(5) = %d, %v, want 1", n, err)
+	}
+	if tableExists(t, db, "category") || appliedVersions(t, db) != 0 {
+		t.Fatal("Down(5) did not revert all migrations")
+	}
+}
+
+func TestMigratorFailure(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	source := testSource()
+	source["000002_create_tag.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE tag (id BIGINT);\nINSERT INTO missing VALUES (1);")}
+	m := newTestMigrator(t, db, source)
+
+	// 失败的迁移不记录，之前成功的迁移保留
+	if n, err := m.Up(ctx); err == nil || n != 1 {
+		t.Fatalf("Up() = %d, %v, want 1 and error", n, err)
+	}
+	if appliedVersions(t, db) != 1 {
+		t.Errorf("records = %d, want 1", appliedVersions(t, db))
+	}
+	if lockHeld("idrm_migrate_" + t.Name()) {
+		t.Error("lock not released after failed Up()")
+	}
+}
+
+func TestMigratorLocked(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := newTestMigrator(t, db, testSource())
+
+	// 其他实例持有锁
+	lockName := "idrm_migrate_" + t.Name()
+	getLock(lockName, 0)
+	defer releaseLockFunc(lockName)
+
+	if _, err := m.Up(ctx); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Up() error = %v, want %v", err, ErrLocked)
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Down() error = %v, want %v", err, ErrLocked)
+	}
+	if tableExists(t, db, "category") {
+		t.Error("migration applied without the lock")
+	}
+}
+
+func TestMigratorVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		data       string // 为空时删除文件
+		wantErr    error
+		wantStatus func(s []Status) bool
+	}{
+		{
+			name: "up文件被修改", file: "000001_create_category.up.sql",
+			data:       "CREATE TABLE category (id BIGINT, name TEXT);",
+			wantErr:    ErrChecksumMismatch,
+			wantStatus: func(s []Status) bool { return s[0].Modified && !s[1].Modified },
+		},
+		{
+			name: "down文件被修改", file: "000002_create_tag.down.sql",
+			data:       "DROP TABLE IF EXISTS tag;",
+			wantErr:    ErrChecksumMismatch,
+			wantStatus: func(s []Status) bool { return !s[0].Modified && s[1].Modified },
+		},
+		{
+			name: "已执行的迁移文件缺失", file: "000002_create_tag.up.sql",
+			wantErr:    ErrMissingFile,
+			wantStatus: func(s []Status) bool { return len(s) == 2 && s[1].Missing },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := openTestDB(t)
+			if _, err := newTestMigrator(t, db, testSource()).Up(ctx); err != nil {
+				t.Fatalf("Up() error = %v", err)
+			}
+
+			source := testSource()
+			if tt.data == "" {
+				delete(source, tt.file)
+				delete(source, "000002_create_tag.down.sql")
+			} else {
+				source[tt.file] = &fstest.MapFile{Data: []byte(tt.data)}
+			}
+			m := newTestMigrator(t, db, source)
+
+			if _, err := m.Up(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Up() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := m.Down(ctx, 1); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Down() error = %v, want %v", err, tt.wantErr)
+			}
+			if appliedVersions(t, db) != 2 {
+				t.Errorf("records = %d, want 2", appliedVersions(t, db))
+			}
+
+			statuses, err := m.Status(ctx)
+			if err != nil || !tt.wantStatus(statuses) {
+				t.Errorf("Status() = %+v, %v", statuses, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fileNamePattern 迁移文件命名：{version}_{name}.up.sql / {version}_{name}.down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 单个迁移
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 和 down 文件内容的 SHA-256，用于检测已执行的迁移文件被修改
+}
+
+// Load 从文件系统加载迁移（按版本升序）
+func Load(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		if err := loadFile(source, entry.Name(), byVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up file", m.Version, m.Name)
+		}
+		m.Checksum = checksum(m.Up, m.Down)
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// loadFile 加载单个迁移文件
+func loadFile(source fs.FS, name string, byVersion map[int64]*Migration) error {
+	match := fileNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return fmt.Errorf("invalid migration file name: %s", name)
+	}
+
+	version, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid migration version %s: %w", name, err)
+	}
+
+	content, err := fs.ReadFile(source, name)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+
+	m, ok := byVersion[version]
+	if !ok {
+		m = &Migration{Version: version, Name: match[2]}
+		byVersion[version] = m
+	} else if m.Name != match[2] {
+		return fmt.Errorf("migration version %d has conflicting names: %s, %s", version, m.Name, match[2])
+	}
+
+	if match[3] == "up" {
+		m.Up = string(content)
+	} else {
+		m.Down = string(content)
+	}
+	return nil
+}
+
+// checksum 计算 up 和 down 文件内容的校验和（以长度前缀区分两个文件的边界）
+func checksum(up, down string) string {
+	h := sha256.New()
+	for _, content := range []string{up, down} {
+		fmt.Fprintf(h, "%d:", len(content))
+		h.Write([]byte(content))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// splitStatements 按行尾分号拆分 SQL 语句，忽略注释行和空行
+// 不支持在同一行内书写多条语句或在字符串中跨行使用分号
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,83 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	source := fstest.MapFS{
+		"000002_add_index.up.sql":         {Data: []byte("CREATE INDEX idx_name ON category (name);")},
+		"000002_add_index.down.sql":       {Data: []byte("DROP INDEX idx_name ON category;")},
+		"000001_create_category.up.sql":   {Data: []byte("CREATE TABLE category (id BIGINT);")},
+		"000001_create_category.down.sql": {Data: []byte("DROP TABLE category;")},
+		"README.md":                       {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(source)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("Load() len = %d, want 2", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Errorf("Load() not sorted: %d, %d", migrations[0].Version, migrations[1].Version)
+	}
+	if migrations[0].Name != "create_category" || migrations[0].Down == "" || migrations[0].Checksum == "" {
+		t.Errorf("Load() migration = %+v", migrations[0])
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source fstest.MapFS
+	}{
+		{
+			name:   "文件名不合法",
+			source: fstest.MapFS{"create_category.up.sql": {Data: []byte("SELECT 1;")}},
+		},
+		{
+			name:   "缺少up文件",
+			source: fstest.MapFS{"000001_create_category.down.sql": {Data: []byte("SELECT 1;")}},
+		},
+		{
+			name: "版本名称冲突",
+			source: fstest.MapFS{
+				"000001_a.up.sql":   {Data: []byte("SELECT 1;")},
+				"000001_b.down.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.source); err == nil {
+				t.Error("Load() expected error")
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- 注释
+CREATE TABLE a (
+    id BIGINT
+);
+
+INSERT INTO a VALUES (1);
+-- 只有注释`
+
+	got := splitStatements(script)
+	if len(got) != 2 {
+		t.Fatalf("splitStatements() len = %d, want 2: %q", len(got), got)
+	}
+	if got[1] != "INSERT INTO a VALUES (1);" {
+		t.Errorf("splitStatements()[1] = %q", got[1])
+	}
+
+	if got := splitStatements("-- 基线版本\n"); len(got) != 0 {
+		t.Errorf("splitStatements(comment only) = %q, want empty", got)
+	}
+}
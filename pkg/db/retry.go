@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"idrm/pkg/telemetry/trace"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// Retrier 数据库操作重试器
+// 仅对幂等读和完整事务重试；事务外的非幂等写操作只执行一次。
+// InitGorm / OpenSQL 按 Config.Retry 自动重试事务外的查询，事务需通过 Trans / TransSQL 整体重试
+type Retrier struct {
+	cfg     RetryConfig
+	retryOn map[string]bool
+}
+
+// NewRetrier 创建重试器
+func NewRetrier(cfg RetryConfig) *Retrier {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.Multiplier < 1 {
+		cfg.Multiplier = 1
+	}
+
+	retryOn := make(map[string]bool, len(cfg.RetryOn))
+	for _, reason := range cfg.RetryOn {
+		retryOn[reason] = true
+	}
+
+	return &Retrier{cfg: cfg, retryOn: retryOn}
+}
+
+// Read 执行幂等读操作，遇到可重试错误按策略重试
+// 单条查询已由 GORM/SQLx 层自动重试，Read 用于需要整体重新执行的多步读取
+func (r *Retrier) Read(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.do(ctx, "read", fn)
+}
+
+// Exec 执行事务外的非幂等写操作，不重试
+func (r *Retrier) Exec(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Translate(fn(ctx))
+}
+
+// Trans 在 GORM 事务中执行 fn，整个事务失败时可重试
+func (r *Retrier) Trans(ctx context.Context, gormDB *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return r.do(ctx, "trans", func(ctx context.Context) error {
+		return gormDB.WithContext(ctx).Transaction(fn)
+	})
+}
+
+// TransSQL 在 database/sql 事务中执行 fn，整个事务失败时可重试
+func (r *Retrier) TransSQL(ctx context.Context, sqlDB *sql.DB, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return r.do(ctx, "trans", func(ctx context.Context) error {
+		return execTx(ctx, sqlDB, fn)
+	})
+}
+
+// execTx 执行单次 database/sql 事务
+func execTx(ctx context.Context, sqlDB *sql.DB, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	if err := fn(ctx, tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// do 按策略执行并重试，返回转换后的错误
+func (r *Retrier) do(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	return Translate(r.retry(ctx, op, fn, nil))
+}
+
+// retry 按策略执行并重试，返回最后一次的原始错误；skip 非空时不重试其返回 true 的错误类型
+func (r *Retrier) retry(ctx context.Context, op string, fn func(ctx context.Context) error, skip func(reason string) bool) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		reason := r.reason(err)
+		if reason == "" || (skip != nil && skip(reason)) || attempt >= r.cfg.MaxAttempts {
+			return err
+		}
+
+		backoff := r.backoff(attempt)
+		trace.AddEvent(trace.GetSpan(ctx), "db.retry",
+			attribute.String("db.operation", op),
+			attribute.Int("db.retry.attempt", attempt),
+			attribute.String("db.retry.reason", reason),
+			attribute.Int64("db.retry.backoff_ms", backoff.Milliseconds()),
+			attribute.String("error", err.Error()),
+		)
+
+		if waitErr := sleep(ctx, backoff); waitErr != nil {
+			return err
+		}
+	}
+}
+
+// reason 返回按配置允许重试的错误类型
+func (r *Retrier) reason(err error) string {
+	reason := retryReason(err)
+	if reason == "" {
+		return ""
+	}
+	if len(r.retryOn) > 0 && !r.retryOn[reason] {
+		return ""
+	}
+	return reason
+}
+
+// backoff 计算第 attempt 次失败后的退避时间（指数退避 + 抖动）
+func (r *Retrier) backoff(attempt int) time.Duration {
+	d := float64(r.cfg.InitialBackoff) * math.Pow(r.cfg.Multiplier, float64(attempt-1))
+	if maxBackoff := float64(r.cfg.MaxBackoff); maxBackoff > 0 && d > maxBackoff {
+		d = maxBackoff
+	}
+	if r.cfg.Jitter > 0 {
+		d += d * r.cfg.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(d * float64(time.Millisecond))
+}
+
+// sleep 等待退避时间，ctx 取消时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,325 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"idrm/pkg/errorx"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errDeadlock = &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+
+func TestRetrier(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       RetryConfig
+		exec      bool  // 使用 Exec（非幂等写）
+		failTimes int   // 前 N 次返回 err
+		err       error // 返回的错误
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "死锁重试后成功",
+			cfg:       RetryConfig{MaxAttempts: 3, InitialBackoff: 1},
+			failTimes: 2,
+			err:       errDeadlock,
+			wantCalls: 3,
+		},
+		{
+			name:      "超过最大次数",
+			cfg:       RetryConfig{MaxAttempts: 3, InitialBackoff: 1},
+			failTimes: 5,
+			err:       errDeadlock,
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "不可重试错误",
+			cfg:       RetryConfig{MaxAttempts: 3, InitialBackoff: 1},
+			failTimes: 5,
+			err:       errors.New("syntax error"),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "RetryOn未包含死锁",
+			cfg:       RetryConfig{MaxAttempts: 3, InitialBackoff: 1, RetryOn: []string{RetryOnConnection}},
+			failTimes: 5,
+			err:       errDeadlock,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "非幂等写不重试",
+			cfg:       RetryConfig{MaxAttempts: 3, InitialBackoff: 1},
+			exec:      true,
+			failTimes: 5,
+			err:       errDeadlock,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRetrier(tt.cfg)
+
+			calls := 0
+			fn := func(ctx context.Context) error {
+				calls++
+				if calls <= tt.failTimes {
+					return tt.err
+				}
+				return nil
+			}
+
+			var err error
+			if tt.exec {
+				err = r.Exec(context.Background(), fn)
+			} else {
+				err = r.Read(context.Background(), fn)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetrier_Backoff(t *testing.T) {
+	r := NewRetrier(RetryConfig{MaxAttempts: 5, InitialBackoff: 50, MaxBackoff: 120, Multiplier: 2})
+
+	want := []int64{50, 100, 120, 120}
+	for i, w := range want {
+		if got := r.backoff(i + 1).Milliseconds(); got != w {
+			t.Errorf("backoff(%d) = %dms, want %dms", i+1, got, w)
+		}
+	}
+}
+
+// flaky 前 fails 次查询返回 err
+type flaky struct {
+	fails int
+	err   error
+	calls int
+}
+
+func (f *flaky) next() error {
+	f.calls++
+	if f.calls <= f.fails {
+		return f.err
+	}
+	return nil
+}
+
+// sqliteConnector 以 SQLite 实现 driver.Connector，查询和执行前经过 flaky 注入错误
+type sqliteConnector struct {
+	dsn   string
+	flaky *flaky
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	base, err := (&sqlite3.SQLiteDriver{}).Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &flakyConn{Conn: base, flaky: c.flaky}, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+// flakyConn 查询和执行前注入错误的 SQLite 连接
+type flakyConn struct {
+	driver.Conn
+	flaky *flaky
+}
+
+func (c *flakyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.flaky != nil {
+		if err := c.flaky.next(); err != nil {
+			return nil, err
+		}
+	}
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *flakyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.flaky != nil {
+		if err := c.flaky.next(); err != nil {
+			return nil, err
+		}
+	}
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+// openTestSQL 打开经过驱动层包装的 SQLite 连接并建表
+func openTestSQL(t *testing.T, cfg Config, f *flaky) *sql.DB {
+	t.Helper()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "sql.db") + "?_busy_timeout=5000"
+	sqlDB := sql.OpenDB(wrapConnector(&sqliteConnector{dsn: dsn, flaky: f}, newQueryTracer(cfg), NewRetrier(cfg.Retry)))
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if _, err := sqlDB.Exec("CREATE TABLE item (id INTEGER PRIMARY KEY, code TEXT)"); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	if _, err := sqlDB.Exec("INSERT INTO item (id, code) VALUES (1, 'C1')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	return sqlDB
+}
+
+// retryLayerCases GORM 和 SQLx 层共用的重试用例
+var retryLayerCases = []struct {
+	name      string
+	inTx      bool
+	fails     int
+	err       error
+	wantCalls int
+	wantCode  int // 0 表示无错误
+}{
+	{name: "事务外死锁重试后成功", fails: 2, err: errDeadlock, wantCalls: 3},
+	{name: "超过最大次数", fails: 5, err: errDeadlock, wantCalls: 3, wantCode: errorx.ErrCodeDatabase},
+	{name: "不可重试错误", fails: 5, err: errors.New("syntax error"), wantCalls: 1, wantCode: errorx.ErrCodeDatabase},
+	{name: "事务中的查询不单独重试", inTx: true, fails: 5, err: errDeadlock, wantCalls: 1, wantCode: errorx.ErrCodeDatabase},
+}
+
+func TestSQLRetry(t *testing.T) {
+	cfg := Config{Retry: RetryConfig{MaxAttempts: 3, InitialBackoff: 1}}
+
+	for _, tt := range retryLayerCases {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &flaky{}
+			sqlDB := openTestSQL(t, cfg, f)
+			ctx := context.Background()
+			*f = flaky{fails: tt.fails, err: tt.err}
+
+			var code string
+			var err error
+			if tt.inTx {
+				err = execTx(ctx, sqlDB, func(ctx context.Context, tx *sql.Tx) error {
+					return QueryRow(ctx, tx, "SELECT code FROM item WHERE id = ?", 1).Scan(&code)
+				})
+			} else {
+				err = QueryRow(ctx, sqlDB, "SELECT code FROM item WHERE id = ?", 1).Scan(&code)
+			}
+
+			assertRetry(t, err, f.calls, tt.wantCalls, tt.wantCode)
+			if tt.wantCode == 0 && code != "C1" {
+				t.Errorf("code = %q, want C1", code)
+			}
+		})
+	}
+}
+
+// flakyPool 查询前注入错误的 GORM 连接池
+type flakyPool struct {
+	gorm.ConnPool
+	flaky *flaky
+}
+
+func (p *flakyPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if err := p.flaky.next(); err != nil {
+		return nil, err
+	}
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+// flakyTxPool 事务中的 flakyPool
+type flakyTxPool struct {
+	flakyPool
+}
+
+func (p *flakyTxPool) Commit() error   { return nil }
+func (p *flakyTxPool) Rollback() error { return nil }
+
+type retryItem struct {
+	Id   int64
+	Code string
+}
+
+func (retryItem) TableName() string { return "item" }
+
+// openTestGorm 打开 SQLite GORM 连接并建表（未注册 idrm 回调）
+func openTestGorm(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	gormDB, err := gorm.Open(gormsqlite.Open(filepath.Join(t.TempDir(), "gorm.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+	if sqlDB, err := gormDB.DB(); err == nil {
+		t.Cleanup(func() { _ = sqlDB.Close() })
+	}
+	if err := gormDB.Exec("CREATE TABLE item (id INTEGER PRIMARY KEY, code TEXT); INSERT INTO item VALUES (1, 'C1');").Error; err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	return gormDB
+}
+
+func TestGormRetry(t *testing.T) {
+	for _, tt := range retryLayerCases {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB := openTestGorm(t)
+			if err := registerRetryCallbacks(gormDB, NewRetrier(RetryConfig{MaxAttempts: 3, InitialBackoff: 1})); err != nil {
+				t.Fatalf("register retry: %v", err)
+			}
+			if err := registerErrorCallbacks(gormDB); err != nil {
+				t.Fatalf("register errors: %v", err)
+			}
+
+			f := &flaky{fails: tt.fails, err: tt.err}
+			pool := flakyPool{ConnPool: gormDB.Statement.ConnPool, flaky: f}
+			if tt.inTx {
+				gormDB.Statement.ConnPool = &flakyTxPool{flakyPool: pool}
+			} else {
+				gormDB.Statement.ConnPool = &pool
+			}
+
+			var data retryItem
+			err := gormDB.WithContext(context.Background()).First(&data, 1).Error
+
+			assertRetry(t, err, f.calls, tt.wantCalls, tt.wantCode)
+			if tt.wantCode == 0 && data.Code != "C1" {
+				t.Errorf("code = %q, want C1", data.Code)
+			}
+		})
+	}
+}
+
+// assertRetry 断言查询次数和转换后的错误码
+func assertRetry(t *testing.T, err error, calls, wantCalls, wantCode int) {
+	t.Helper()
+
+	if calls != wantCalls {
+		t.Errorf("calls = %d, want %d", calls, wantCalls)
+	}
+	if wantCode == 0 {
+		if err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		return
+	}
+	if codeErr, ok := errorx.FromError(err); !ok || codeErr.GetCode() != wantCode {
+		t.Errorf("error = %v, want code %d", err, wantCode)
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// OpenSQL 打开 database/sql 连接（供 SQLx 实现使用）
+// 驱动层自动创建查询 Span、输出慢查询日志、按 cfg.Retry 重试事务外的查询，并将错误转换为 errorx 错误码；
+// sql.ErrNoRows 由 database/sql 在 Scan 时产生，单行查询使用 QueryRow 统一转换
+func OpenSQL(cfg Config) (*sql.DB, error) {
+	mysqlCfg, err := mysql.ParseDSN(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+
+	connector, err := mysql.NewConnector(mysqlCfg)
+	if err != nil {
+		return nil, fmt.Errorf("create connector: %w", err)
+	}
+	return OpenConnector(connector, cfg)
+}
+
+// OpenConnector 使用指定驱动连接器打开 database/sql 连接，包装方式与 OpenSQL 相同
+// 一致性测试通过它以 SQLite 驱动运行生产配置
+func OpenConnector(connector driver.Connector, cfg Config) (*sql.DB, error) {
+	sqlDB := sql.OpenDB(wrapConnector(connector, newQueryTracer(cfg), NewRetrier(cfg.Retry)))
+	setPool(sqlDB, cfg)
+
+	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("ping: %w", err)
+	}
+	return sqlDB, nil
+}
+
+// setPool 设置连接池参数
+func setPool(sqlDB *sql.DB, cfg Config) {
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
+}
+
+// Querier *sql.DB、*sql.Tx 和 *sql.Conn 的公共查询方法
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Row 单行查询结果，Scan 错误（包括 sql.ErrNoRows）转换为 errorx 错误码
+type Row struct {
+	row *sql.Row
+}
+
+// QueryRow 查询单行，SQLx 实现使用它代替 QueryRowContext，调用方不会得到原始的 sql.ErrNoRows
+//
+//	err := db.QueryRow(ctx, m.conn, "SELECT id, name FROM category WHERE id = ?", id).Scan(&c.Id, &c.Name)
+//	// 不存在时 err 为 errorx.ErrCodeNotFound，errors.Is(err, sql.ErrNoRows) 仍成立
+func QueryRow(ctx context.Context, q Querier, query string, args ...interface{}) *Row {
+	return &Row{row: q.QueryRowContext(ctx, query, args...)}
+}
+
+// Scan 扫描结果到 dest
+func (r *Row) Scan(dest ...interface{}) error {
+	return Translate(r.row.Scan(dest...))
+}
+
+// Err 查询错误（不扫描结果）
+func (r *Row) Err() error {
+	return Translate(r.row.Err())
+}
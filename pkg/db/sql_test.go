@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"idrm/pkg/errorx"
+
+	"gorm.io/driver/sqlite"
+)
+
+func TestQueryRow(t *testing.T) {
+	conn, err := sql.Open(sqlite.DriverName, "file:"+filepath.Join(t.TempDir(), "query.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec("CREATE TABLE item (id INTEGER PRIMARY KEY, code TEXT); INSERT INTO item VALUES (1, 'C1');"); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		id       int64
+		wantCode int // 0 表示无错误
+	}{
+		{name: "查询成功", query: "SELECT code FROM item WHERE id = ?", id: 1},
+		{name: "无数据转换为不存在", query: "SELECT code FROM item WHERE id = ?", id: 2, wantCode: errorx.ErrCodeNotFound},
+		{name: "语句错误转换为数据库错误", query: "SELECT code FROM missing WHERE id = ?", id: 1, wantCode: errorx.ErrCodeDatabase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var code string
+			err := QueryRow(context.Background(), conn, tt.query, tt.id).Scan(&code)
+			if tt.wantCode == 0 {
+				if err != nil || code != "C1" {
+					t.Fatalf("Scan() = %q, %v", code, err)
+				}
+				return
+			}
+
+			codeErr, ok := errorx.FromError(err)
+			if !ok || codeErr.GetCode() != tt.wantCode {
+				t.Fatalf("Scan() error = %v, want code %d", err, tt.wantCode)
+			}
+			if tt.wantCode == errorx.ErrCodeNotFound && !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("Scan() lost sql.ErrNoRows: %v", err)
+			}
+		})
+	}
+}
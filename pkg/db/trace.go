@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/trace"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// dbSystem 数据库类型
+const dbSystem = "mysql"
+
+// GORM 回调名称及实例键
+const (
+	callbackTraceBefore = "idrm:trace_before"
+	callbackTraceAfter  = "idrm:trace_after"
+	instanceSpanKey     = "idrm:span"
+	instanceStartKey    = "idrm:start"
+)
+
+var (
+	// stringLiteral SQL 字符串字面量
+	stringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+	// numberLiteral SQL 数字字面量
+	numberLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+)
+
+// queryTracer 查询追踪与慢查询日志
+type queryTracer struct {
+	dbName        string
+	slowThreshold time.Duration
+}
+
+// newQueryTracer 创建查询追踪器
+func newQueryTracer(cfg Config) *queryTracer {
+	return &queryTracer{
+		dbName:        cfg.Database,
+		slowThreshold: time.Duration(cfg.SlowThreshold) * time.Millisecond,
+	}
+}
+
+// start 开始客户端 Span
+func (q *queryTracer) start(ctx context.Context, operation string) (context.Context, oteltrace.Span, time.Time) {
+	ctx, span := trace.StartClient(ctx, "db."+operation,
+		attribute.String("db.system", dbSystem),
+		attribute.String("db.name", q.dbName),
+		attribute.String("db.operation", operation),
+	)
+	return ctx, span, time.Now()
+}
+
+// end 结束 Span，记录语句、影响行数、错误并输出慢查询日志
+// rows 小于 0 表示影响行数未知
+func (q *queryTracer) end(ctx context.Context, span oteltrace.Span, start time.Time, statement string, rows int64, err error) {
+	duration := time.Since(start)
+	statement = redactSQL(statement)
+
+	span.SetAttributes(attribute.String("db.statement", statement))
+	if rows >= 0 {
+		span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+	}
+	// 按转换后的错误码分类：记录不存在、唯一键冲突等业务错误不标记 Span 为 Error
+	trace.SetError(span, Translate(err))
+	span.End()
+
+	if q.slowThreshold > 0 && duration >= q.slowThreshold {
+		log.FromContext(ctx).Sloww("slow query",
+			logx.Field("db.name", q.dbName),
+			logx.Field("db.statement", statement),
+			logx.Field("duration_ms", duration.Milliseconds()),
+			logx.Field("rows_affected", rows),
+		)
+	}
+}
+
+// registerTraceCallbacks 注册 GORM 追踪回调
+func registerTraceCallbacks(gormDB *gorm.DB, q *queryTracer) error {
+	cb := gormDB.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, p := range processors {
+		operation := p.operation
+		if err := p.before(callbackTraceBefore, func(tx *gorm.DB) {
+			ctx, span, start := q.start(tx.Statement.Context, operation)
+			tx.Statement.Context = ctx
+			tx.InstanceSet(instanceSpanKey, span)
+			tx.InstanceSet(instanceStartKey, start)
+		}); err != nil {
+			return err
+		}
+		if err := p.after(callbackTraceAfter, func(tx *gorm.DB) {
+			span, ok := tx.InstanceGet(instanceSpanKey)
+			if !ok {
+				return
+			}
+			start, _ := tx.InstanceGet(instanceStartKey)
+			q.end(tx.Statement.Context, span.(oteltrace.Span), start.(time.Time),
+				tx.Statement.SQL.String(), tx.Statement.RowsAffected, tx.Error)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactSQL 将 SQL 中的字面量替换为占位符，避免参数泄露到追踪和日志中
+func redactSQL(statement string) string {
+	statement = stringLiteral.ReplaceAllString(statement, "?")
+	return numberLiteral.ReplaceAllString(statement, "?")
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/telemetry/trace"
+	"idrm/pkg/telemetry/trace/tracetest"
+
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRedactSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "占位符保持不变",
+			sql:  "SELECT * FROM `category` WHERE `id` = ? LIMIT ?",
+			want: "SELECT * FROM `category` WHERE `id` = ? LIMIT ?",
+		},
+		{
+			name: "字符串字面量",
+			sql:  "SELECT * FROM user WHERE mobile = '13800138000' AND name = 'O''Brien'",
+			want: "SELECT * FROM user WHERE mobile = ? AND name = ?",
+		},
+		{
+			name: "数字字面量",
+			sql:  "UPDATE t1 SET score = 98.5 WHERE id IN (1, 2)",
+			want: "UPDATE t1 SET score = ? WHERE id IN (?, ?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactSQL(tt.sql); got != tt.want {
+				t.Errorf("redactSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+var errDupEntry = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'C1' for key 'uk_code'"}
+
+func TestTraceSpans(t *testing.T) {
+	rec := tracetest.New(t)
+	cfg := Config{Database: "idrm_test"}
+
+	f := &flaky{}
+	sqlDB := openTestSQL(t, cfg, f)
+	gormDB := openTestGorm(t)
+	if err := registerTraceCallbacks(gormDB, newQueryTracer(cfg)); err != nil {
+		t.Fatalf("register trace callbacks: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		run       func(ctx context.Context) error
+		span      string
+		statement string
+		status    codes.Code
+		code      int   // error.code 属性，0 表示无错误
+		rows      int64 // -1 表示不记录影响行数
+	}{
+		{
+			name: "驱动层查询脱敏字面量",
+			run: func(ctx context.Context) error {
+				var code string
+				return sqlDB.QueryRowContext(ctx, "SELECT code FROM item WHERE code = 'C1' AND id = 1").Scan(&code)
+			},
+			span: "db.query", statement: "SELECT code FROM item WHERE code = ? AND id = ?", status: codes.Unset, rows: -1,
+		},
+		{
+			name: "驱动层写入记录影响行数",
+			run: func(ctx context.Context) error {
+				_, err := sqlDB.ExecContext(ctx, "UPDATE item SET code = ? WHERE id = 1", "C9")
+				return err
+			},
+			span: "db.exec", statement: "UPDATE item SET code = ? WHERE id = ?", status: codes.Unset, rows: 1,
+		},
+		{
+			name: "驱动层错误标记状态",
+			run: func(ctx context.Context) error {
+				_, err := sqlDB.QueryContext(ctx, "SELECT code FROM missing")
+				return err
+			},
+			span: "db.query", statement: "SELECT code FROM missing", status: codes.Error, code: errorx.ErrCodeDatabase, rows: -1,
+		},
+		{
+			name: "驱动层唯一键冲突不标记错误",
+			run: func(ctx context.Context) error {
+				*f = flaky{fails: 1, err: errDupEntry}
+				defer func() { *f = flaky{} }()
+				_, err := sqlDB.ExecContext(ctx, "INSERT INTO item (id, code) VALUES (2, 'C1')")
+				return err
+			},
+			span: "db.exec", statement: "INSERT INTO item (id, code) VALUES (?, ?)", status: codes.Unset, code: errorx.ErrCodeAlreadyExists, rows: -1,
+		},
+		{
+			name: "GORM查询脱敏字面量",
+			run: func(ctx context.Context) error {
+				var data retryItem
+				return gormDB.WithContext(ctx).Where("code = 'C1'").First(&data).Error
+			},
+			span: "db.query", statement: "SELECT * FROM `item` WHERE code = ? ORDER BY `item`.`id` LIMIT ?", status: codes.Unset, rows: 1,
+		},
+		{
+			name: "GORM记录不存在不标记错误",
+			run: func(ctx context.Context) error {
+				var data retryItem
+				return gormDB.WithContext(ctx).First(&data, 99).Error
+			},
+			span: "db.query", statement: "SELECT * FROM `item` WHERE `item`.`id` = ? ORDER BY `item`.`id` LIMIT ?", status: codes.Unset, code: errorx.ErrCodeNotFound, rows: 0,
+		},
+		{
+			name: "GORM唯一键冲突不标记错误",
+			run: func(ctx context.Context) error {
+				tx := gormDB.WithContext(ctx)
+				tx.Statement.ConnPool = &flakyPool{ConnPool: tx.Statement.ConnPool, flaky: &flaky{fails: 1, err: errDupEntry}}
+				return tx.Create(&retryItem{Id: 2, Code: "C1"}).Error
+			},
+			span: "db.create", statement: "INSERT INTO `item` (`code`,`id`) VALUES (?,?) RETURNING `id`", status: codes.Unset, code: errorx.ErrCodeAlreadyExists, rows: 0,
+		},
+		{
+			name: "GORM错误标记状态",
+			run: func(ctx context.Context) error {
+				return gormDB.WithContext(ctx).Exec("DELETE FROM missing WHERE id = 1").Error
+			},
+			span: "db.raw", statement: "DELETE FROM missing WHERE id = ?", status: codes.Error, code: errorx.ErrCodeDatabase, rows: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec.Reset()
+
+			ctx, parent := trace.Start(context.Background(), "handler")
+			err := tt.run(ctx)
+			parent.End()
+			if (err != nil) != (tt.code != 0) {
+				t.Fatalf("run() error = %v, want error %v", err, tt.code != 0)
+			}
+
+			rec.AssertCount(t, 2)
+			span := rec.Span(t, tt.span).
+				HasKind(oteltrace.SpanKindClient).
+				HasParent("handler").
+				HasAttribute("db.system", dbSystem).
+				HasAttribute("db.name", "idrm_test").
+				HasAttribute("db.statement", tt.statement).
+				HasStatus(tt.status)
+			if tt.rows >= 0 {
+				span.HasAttribute("db.rows_affected", tt.rows)
+			} else {
+				span.NoAttribute("db.rows_affected")
+			}
+			if tt.code != 0 {
+				span.HasAttribute(trace.AttrErrorCode, tt.code)
+			} else {
+				span.NoAttribute(trace.AttrErrorCode)
+			}
+			if tt.status == codes.Error {
+				span.HasEvent("exception")
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package errorx
 
+import "errors"
+
 // 错误码定义
 const (
 	// 系统错误 (10000-19999)
@@ -60,10 +62,16 @@ var errMsgMap = map[int]string{
 type CodeError struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
+
+	// cause 原始错误（不写入响应，仅用于错误链和日志）
+	cause error
 }
 
-// Error 实现error接口
+// Error 实现error接口，包装了原始错误时附带原始错误信息
 func (e *CodeError) Error() string {
+	if e.cause != nil {
+		return e.Msg + ": " + e.cause.Error()
+	}
 	return e.Msg
 }
 
@@ -77,6 +85,11 @@ func (e *CodeError) GetMsg() string {
 	return e.Msg
 }
 
+// Unwrap 返回原始错误，支持 errors.Is / errors.As
+func (e *CodeError) Unwrap() error {
+	return e.cause
+}
+
 // New 创建错误
 func New(code int, msg string) error {
 	return &CodeError{
@@ -104,3 +117,23 @@ func NewWithMsg(code int, msg string) error {
 		Msg:  msg,
 	}
 }
+
+// Wrap 使用错误码包装原始错误
+// 对外消息（GetMsg）取错误码默认消息，原始错误保留在错误链中并附在 Error() 中
+func Wrap(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	codeErr := NewWithCode(code).(*CodeError)
+	codeErr.cause = err
+	return codeErr
+}
+
+// FromError 从错误链中提取 CodeError
+func FromError(err error) (*CodeError, bool) {
+	var codeErr *CodeError
+	if errors.As(err, &codeErr) {
+		return codeErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"idrm/pkg/telemetry"
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/telemetry/log"
+)
+
+const (
+	// auditMaxBody is the largest JSON request body recorded as After
+	auditMaxBody = 64 << 10
+	// auditMaxResponse is the response prefix inspected for the business result code
+	auditMaxResponse = 4 << 10
+)
+
+// AuditRoute annotates a route with the audit record written for each request
+type AuditRoute struct {
+	Action      string // audit.ActionCreate, audit.ActionUpdate, ...
+	Resource    string // audit.ResourceCategory, ...
+	CaptureBody bool   // record the JSON request body (masked) as After
+}
+
+// AuditRoutes maps "METHOD /route/template" (as registered, see RegisterRoutes) to annotations:
+//
+//	middleware.AuditRoutes{
+//		"POST /api/v1/category":       {Action: audit.ActionCreate, Resource: audit.ResourceCategory, CaptureBody: true},
+//		"DELETE /api/v1/category/:id": {Action: audit.ActionDelete, Resource: audit.ResourceCategory},
+//	}
+type AuditRoutes map[string]AuditRoute
+
+// Audit records audit logs for annotated routes using the default telemetry instance
+func Audit(routes AuditRoutes) func(http.HandlerFunc) http.HandlerFunc {
+	return AuditWith(nil, routes)
+}
+
+// AuditWith records audit logs for annotated routes using the given telemetry instance
+// (nil uses the default instance set by telemetry.Init). Requests to other routes pass through.
+//
+// Request ID and trace ID are taken from the request context, so register it after RequestID
+// and Trace. User and tenant are read when the request completes, including those set by
+// route-level authentication through log.WithUser/WithTenant. Handlers can add details
+// through audit.HelperFromContext(ctx) (e.g. WithBefore, WithExtra).
+//
+// A request fails the audit when the status is >= 400, the handler panics, or the JSON
+// response carries a non-zero business code (pkg/response writes errors with status 200).
+func AuditWith(p *telemetry.Provider, routes AuditRoutes) func(http.HandlerFunc) http.HandlerFunc {
+	table := make(map[string]AuditRoute, len(routes))
+	for key, route := range routes {
+		method, path, _ := strings.Cut(strings.TrimSpace(key), " ")
+		table[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = route
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			route := MatchRoute(r)
+			annotation, ok := table[r.Method+" "+route]
+			if !ok {
+				next(w, r)
+				return
+			}
+
+			// The log scope makes user and tenant set downstream (route-level auth calling
+			// log.WithUser/WithTenant) readable from this request's context after next returns
+			r = r.WithContext(log.WithScope(r.Context()))
+			helper := p.Audit().NewHelper(r.Context()).
+				WithAction(annotation.Action).
+				WithResource(annotation.Resource).
+				WithRoute(route)
+			if annotation.CaptureBody && isJSON(r.Header.Get("Content-Type")) && r.Body != nil {
+				captureBody(helper, peekBody(r, auditMaxBody))
+			}
+			r = r.WithContext(audit.ContextWithHelper(r.Context(), helper))
+
+			sw := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK, maxBody: auditMaxResponse}
+			defer func() {
+				// Record the failure, then let Recovery respond
+				if rec := recover(); rec != nil {
+					helper.WithRequest(r).WithStatus(http.StatusInternalServerError).Fail(fmt.Errorf("panic: %v", rec))
+					panic(rec)
+				}
+			}()
+
+			next(sw, r)
+
+			helper.WithRequest(r).WithStatus(sw.statusCode)
+			if err := auditResult(sw); err != nil {
+				helper.Fail(err)
+			} else {
+				helper.Success()
+			}
+		}
+	}
+}
+
+// captureBody records the request body as After, or marks it as too large / invalid
+func captureBody(helper *audit.Helper, body []byte) {
+	if len(body) > auditMaxBody {
+		helper.WithExtra("body", "[body too large]")
+		return
+	}
+	var after interface{}
+	if err := json.Unmarshal(body, &after); err != nil {
+		helper.WithExtra("body", "[invalid json]")
+		return
+	}
+	helper.WithAfter(after)
+}
+
+// auditResult derives the request outcome from the status code and the pkg/response body:
+// {"code":0,"msg":"success"} succeeds, a non-zero numeric code or an HttpError
+// ({"code":"idrm.xxx","description":...}) fails with its message
+func auditResult(sw *statusWriter) error {
+	var body struct {
+		Code        json.RawMessage `json:"code"`
+		Msg         string          `json:"msg"`
+		Description string          `json:"description"`
+	}
+	if isJSON(sw.Header().Get("Content-Type")) && sw.body.Len() <= auditMaxResponse {
+		_ = json.Unmarshal(sw.body.Bytes(), &body)
+	}
+
+	code := strings.Trim(string(body.Code), `"`)
+	msg := body.Msg
+	if msg == "" {
+		msg = body.Description
+	}
+
+	switch {
+	case sw.statusCode >= http.StatusBadRequest:
+		if msg == "" {
+			msg = http.StatusText(sw.statusCode)
+		}
+		return fmt.Errorf("%d %s", sw.statusCode, msg)
+	case code != "" && code != "0" && code != "null":
+		return fmt.Errorf("%s %s", code, msg)
+	}
+	return nil
+}
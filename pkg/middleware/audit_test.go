@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"idrm/pkg/telemetry"
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/telemetry/log"
+
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// auditCollector is a stand-in audit service that keeps the received records
+type auditCollector struct {
+	mu   sync.Mutex
+	logs []audit.AuditLog
+}
+
+func (c *auditCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		AuditLogs []audit.AuditLog `json:"audit_logs"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	c.mu.Lock()
+	c.logs = append(c.logs, body.AuditLogs...)
+	c.mu.Unlock()
+}
+
+// withUser simulates route-level authentication that sets the user on a derived context
+func withUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := log.WithTenant(log.WithUser(r.Context(), "u-1"), "t-1")
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func TestAudit(t *testing.T) {
+	RegisterRoutes([]rest.Route{
+		{Method: http.MethodGet, Path: "/api/v1/category/:id"},
+		{Method: http.MethodPost, Path: "/api/v1/category"},
+		{Method: http.MethodPut, Path: "/api/v1/category/:id"},
+		{Method: http.MethodDelete, Path: "/api/v1/category/:id"},
+	})
+	defer routes.Store(nil)
+
+	auditRoutes := AuditRoutes{
+		"post /api/v1/category":       {Action: audit.ActionCreate, Resource: audit.ResourceCategory, CaptureBody: true},
+		"PUT  /api/v1/category/:id":   {Action: audit.ActionUpdate, Resource: audit.ResourceCategory},
+		"DELETE /api/v1/category/:id": {Action: audit.ActionDelete, Resource: audit.ResourceCategory},
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		handler http.HandlerFunc
+		want    *audit.AuditLog // nil: no record
+	}{
+		{
+			name: "未注解的路由不记录", method: http.MethodGet, path: "/api/v1/category/1", handler: ok,
+		},
+		{
+			name: "按路由模板匹配并记录请求体", method: http.MethodPost, path: "/api/v1/category",
+			body: `{"name":"c1"}`, handler: ok,
+			want: &audit.AuditLog{Action: audit.ActionCreate, Route: "/api/v1/category", Status: http.StatusOK,
+				Success: true, After: map[string]interface{}{"name": "c1"}},
+		},
+		{
+			name: "业务码非0记为失败", method: http.MethodPut, path: "/api/v1/category/7",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"code":100001,"msg":"类别已存在"}`))
+			},
+			want: &audit.AuditLog{Action: audit.ActionUpdate, Route: "/api/v1/category/:id", Status: http.StatusOK,
+				Error: "100001 类别已存在"},
+		},
+		{
+			name: "状态码>=400记为失败", method: http.MethodDelete, path: "/api/v1/category/7",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusForbidden) },
+			want: &audit.AuditLog{Action: audit.ActionDelete, Route: "/api/v1/category/:id", Status: http.StatusForbidden,
+				Error: "403 Forbidden"},
+		},
+		{
+			name: "panic记为失败", method: http.MethodDelete, path: "/api/v1/category/7",
+			handler: func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			want: &audit.AuditLog{Action: audit.ActionDelete, Route: "/api/v1/category/:id", Status: http.StatusInternalServerError,
+				Error: "panic: boom"},
+		},
+		{
+			name: "路由级认证写入的用户和租户", method: http.MethodDelete, path: "/api/v1/category/7", handler: withUser(ok),
+			want: &audit.AuditLog{Action: audit.ActionDelete, Route: "/api/v1/category/:id", Status: http.StatusOK,
+				Success: true, UserID: "u-1", TenantID: "t-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &auditCollector{}
+			srv := httptest.NewServer(collector)
+			defer srv.Close()
+			logger := audit.New(audit.AuditConfig{Enabled: true, Url: srv.URL, FlushInterval: 10 * time.Millisecond}, "idrm-test")
+			p := telemetry.NewProvider("idrm-test", nil, logger, nil)
+
+			handler := AuditWith(p, auditRoutes)(tt.handler)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(log.WithRequestID(req.Context(), "req-1"))
+
+			func() {
+				defer func() { _ = recover() }()
+				handler(httptest.NewRecorder(), req)
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := logger.Close(ctx); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if tt.want == nil {
+				if len(collector.logs) != 0 {
+					t.Errorf("records = %+v, want none", collector.logs)
+				}
+				return
+			}
+			if len(collector.logs) != 1 {
+				t.Fatalf("records = %d, want 1", len(collector.logs))
+			}
+			got := collector.logs[0]
+			if got.Action != tt.want.Action || got.Resource != audit.ResourceCategory || got.Route != tt.want.Route ||
+				got.Status != tt.want.Status || got.Success != tt.want.Success || got.Error != tt.want.Error ||
+				got.UserID != tt.want.UserID || got.TenantID != tt.want.TenantID ||
+				got.RequestID != "req-1" || got.Method != tt.method || got.Path != tt.path {
+				t.Errorf("record = %+v, want %+v", got, tt.want)
+			}
+			if tt.want.After != nil {
+				after, _ := json.Marshal(got.After)
+				want, _ := json.Marshal(tt.want.After)
+				if string(after) != string(want) {
+					t.Errorf("After = %s, want %s", after, want)
+				}
+			}
+		})
+	}
+}
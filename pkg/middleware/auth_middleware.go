@@ -30,7 +30,9 @@ func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
 
 			// TODO: 验证JWT token
 			// 这里需要使用jwt库验证token
-			// 验证成功后，可以将用户信息放入context
+			// 验证成功后，通过 log.WithUser/log.WithTenant 将用户信息放入context，日志自动携带；
+			// 同时通过 trace.WithUserID/trace.WithTenantID 写入 Baggage，传播给下游服务
+			// 作为路由级中间件时在 middleware.Audit 之后执行，log.WithUser/WithTenant 写入的用户和租户由审计中间件在请求结束时自动读取
 			_ = token
 
 			// 调用下一个处理器
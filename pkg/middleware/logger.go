@@ -1,47 +1,141 @@
 package middleware
 
 import (
+	"bytes"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/mask"
+
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
 // Logger logs HTTP requests with detailed information
 func Logger() func(http.HandlerFunc) http.HandlerFunc {
+	return LoggerWithBody(0)
+}
+
+// LoggerWithBody logs HTTP requests and captures JSON request/response bodies
+// up to maxBodyBytes (0 disables capture). Captured bodies and query parameters
+// are masked by mask struct tags and key rules before logging.
+func LoggerWithBody(maxBodyBytes int) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			// 路由写入日志上下文字段，用于按路由覆盖日志级别
+			r = r.WithContext(log.WithRoute(r.Context(), r.URL.Path))
+
+			var reqBody []byte
+			if maxBodyBytes > 0 && isJSON(r.Header.Get("Content-Type")) && r.Body != nil {
+				reqBody = peekBody(r, maxBodyBytes)
+			}
+
 			// Wrap response writer to capture status code
-			sw := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			sw := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK, maxBody: maxBodyBytes}
 
 			// Execute request
 			next(sw, r)
 
 			// Log request details
 			duration := time.Since(start)
-			logx.WithContext(r.Context()).Infow("HTTP Request",
+			fields := []logx.LogField{
 				logx.Field("method", r.Method),
 				logx.Field("path", r.URL.Path),
-				logx.Field("query", r.URL.RawQuery),
+				logx.Field("query", maskQuery(r.URL.RawQuery)),
 				logx.Field("status", sw.statusCode),
 				logx.Field("duration_ms", duration.Milliseconds()),
 				logx.Field("remote_addr", r.RemoteAddr),
 				logx.Field("user_agent", r.UserAgent()),
-				logx.Field("request_id", GetRequestID(r.Context())),
-			)
+			}
+			if len(reqBody) > 0 {
+				fields = append(fields, logx.Field("request_body", maskBody(reqBody, maxBodyBytes)))
+			}
+			if sw.body.Len() > 0 && isJSON(sw.Header().Get("Content-Type")) {
+				fields = append(fields, logx.Field("response_body", maskBody(sw.body.Bytes(), maxBodyBytes)))
+			}
+			log.FromContext(r.Context()).Infow("HTTP Request", fields...)
 		}
 	}
 }
 
+// peekBody 读取请求体前 limit+1 字节用于日志，并还原 r.Body 供后续处理
+func peekBody(r *http.Request, limit int) []byte {
+	buf, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+	if err != nil {
+		return nil
+	}
+	return buf
+}
+
+// maskBody 脱敏 JSON 请求/响应体，超过上限或无法解析时不记录原文
+func maskBody(body []byte, limit int) string {
+	if len(body) > limit {
+		return "[body too large]"
+	}
+	masked, ok := mask.JSON(body)
+	if !ok {
+		return "[invalid json]"
+	}
+	return string(masked)
+}
+
+// maskQuery 按键名规则脱敏查询参数（如 ?token=xxx）
+func maskQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	changed := false
+	for key, vs := range values {
+		rule := mask.KeyRule(key)
+		if rule == "" {
+			continue
+		}
+		for i := range vs {
+			vs[i] = mask.String(rule, vs[i])
+		}
+		changed = true
+	}
+	if !changed {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+// isJSON 判断 Content-Type 是否为 JSON
+func isJSON(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
+
 // statusWriter wraps ResponseWriter to capture status code
+// and, when maxBody > 0, the first maxBody+1 bytes of the response body
 type statusWriter struct {
 	http.ResponseWriter
 	statusCode int
+	maxBody    int
+	body       bytes.Buffer
 }
 
 func (w *statusWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 	w.ResponseWriter.WriteHeader(statusCode)
 }
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if remain := w.maxBody + 1 - w.body.Len(); w.maxBody > 0 && remain > 0 {
+		w.body.Write(p[:min(len(p), remain)])
+	}
+	return w.ResponseWriter.Write(p)
+}
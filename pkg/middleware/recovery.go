@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"runtime/debug"
 
+	"idrm/pkg/telemetry/log"
+
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
@@ -16,12 +18,11 @@ func Recovery() func(http.HandlerFunc) http.HandlerFunc {
 			defer func() {
 				if err := recover(); err != nil {
 					// Log panic with stack trace
-					logx.WithContext(r.Context()).Errorw("Panic recovered",
+					log.FromContext(r.Context()).Errorw("Panic recovered",
 						logx.Field("error", err),
 						logx.Field("stack", string(debug.Stack())),
 						logx.Field("method", r.Method),
 						logx.Field("path", r.URL.Path),
-						logx.Field("request_id", GetRequestID(r.Context())),
 					)
 
 					// Return 500 error
@@ -4,11 +4,11 @@ import (
 	"context"
 	"net/http"
 
+	"idrm/pkg/telemetry/log"
+
 	"github.com/google/uuid"
 )
 
-type requestIDKey struct{}
-
 // RequestID generates and injects request ID into context
 func RequestID() func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
@@ -19,8 +19,8 @@ func RequestID() func(http.HandlerFunc) http.HandlerFunc {
 				requestID = uuid.New().String()
 			}
 
-			// Set to context
-			ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+			// Set to context (also as logx field so every context log carries it)
+			ctx := log.WithRequestID(r.Context(), requestID)
 
 			// Set response header
 			w.Header().Set("X-Request-ID", requestID)
@@ -33,8 +33,5 @@ func RequestID() func(http.HandlerFunc) http.HandlerFunc {
 
 // GetRequestID retrieves request ID from context
 func GetRequestID(ctx context.Context) string {
-	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
-		return id
-	}
-	return ""
+	return log.RequestID(ctx)
 }
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// routeTemplate is a registered route split into path segments
+type routeTemplate struct {
+	path     string
+	segments []string
+	static   int // number of non-parameter segments, more specific routes win
+}
+
+// routes holds registered route templates by method
+var routes atomic.Pointer[map[string][]routeTemplate]
+
+// RegisterRoutes registers route templates used for span names, http.route and audit annotations,
+// call after all handlers are registered:
+//
+//	handler.RegisterHandlers(server, ctx)
+//	middleware.RegisterRoutes(server.Routes())
+func RegisterRoutes(rs []rest.Route) {
+	table := make(map[string][]routeTemplate, len(rs))
+	for _, r := range rs {
+		segments := splitPath(r.Path)
+		static := 0
+		for _, s := range segments {
+			if !strings.HasPrefix(s, ":") {
+				static++
+			}
+		}
+		method := strings.ToUpper(r.Method)
+		table[method] = append(table[method], routeTemplate{path: r.Path, segments: segments, static: static})
+	}
+	routes.Store(&table)
+}
+
+// MatchRoute returns the registered route template matching the request path
+// (e.g. /api/v1/category/:id), or empty when no template matches
+func MatchRoute(r *http.Request) string {
+	table := routes.Load()
+	if table == nil {
+		return ""
+	}
+
+	segments := splitPath(r.URL.Path)
+	best := -1
+	route := ""
+	for _, tpl := range (*table)[r.Method] {
+		if tpl.static > best && tpl.match(segments) {
+			best = tpl.static
+			route = tpl.path
+		}
+	}
+	return route
+}
+
+// match reports whether path segments match the template (":name" matches any segment)
+func (t routeTemplate) match(segments []string) bool {
+	if len(segments) != len(t.segments) {
+		return false
+	}
+	for i, s := range t.segments {
+		if !strings.HasPrefix(s, ":") && s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath splits a path into non-empty segments
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
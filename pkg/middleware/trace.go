@@ -1,30 +1,69 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 
+	"idrm/pkg/telemetry"
 	"idrm/pkg/telemetry/trace"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-// Trace creates OpenTelemetry spans for HTTP requests
+// HeaderTraceResponse is the W3C Trace Context Level 2 response header
+const HeaderTraceResponse = "traceresponse"
+
+// Trace creates OpenTelemetry spans for HTTP requests using the default telemetry instance
 func Trace() func(http.HandlerFunc) http.HandlerFunc {
+	return TraceWith(nil)
+}
+
+// TraceWith creates OpenTelemetry spans for HTTP requests using the given telemetry
+// instance (nil uses the default instance set by telemetry.Init).
+// Upstream context is extracted with the global propagator (see trace.TraceConfig.Propagators),
+// spans are named "METHOD /route/:template" (see RegisterRoutes) and the server span
+// is returned to the caller in the traceresponse header.
+func TraceWith(p *telemetry.Provider) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
+			// Continue upstream trace (gateway, other services) if present
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			// Drop baggage members untrusted callers may not set, copy selected members to log fields
+			ctx = trace.AcceptBaggage(ctx, r.RemoteAddr)
+
+			// Name by route template to keep span names low-cardinality
+			route := MatchRoute(r)
+			spanName := r.Method
+			if route != "" {
+				spanName = r.Method + " " + route
+			}
+
 			// Create Server Span
-			ctx, span := trace.StartServer(r.Context(), r.URL.Path,
-				attribute.String("http.method", r.Method),
-				attribute.String("http.url", r.URL.String()),
-				attribute.String("http.host", r.Host),
-				attribute.String("http.scheme", getScheme(r)),
-				attribute.String("http.user_agent", r.UserAgent()),
-				attribute.String("http.client_ip", getClientIP(r)),
-				attribute.String("http.request_id", GetRequestID(r.Context())),
+			ctx, span := p.Tracer().Start(ctx, spanName,
+				oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+				oteltrace.WithAttributes(
+					attribute.String("http.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("http.url", r.URL.String()),
+					attribute.String(trace.AttrURLPath, r.URL.Path),
+					attribute.String("http.host", r.Host),
+					attribute.String("http.scheme", getScheme(r)),
+					attribute.String("http.user_agent", r.UserAgent()),
+					attribute.String("http.client_ip", trace.ClientIP(r)),
+					attribute.String("http.request_id", GetRequestID(r.Context())),
+				),
 			)
 			defer span.End()
 
+			// Remote context means tracing is disabled and the upstream span was passed through
+			if sc := span.SpanContext(); sc.IsValid() && !sc.IsRemote() {
+				w.Header().Set(HeaderTraceResponse, fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags()))
+			}
+
 			// Update request context
 			r = r.WithContext(ctx)
 
@@ -70,17 +109,3 @@ func getScheme(r *http.Request) string {
 	}
 	return "http"
 }
-
-// getClientIP extracts real client IP
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
-		return ip
-	}
-	// Check X-Real-IP header
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return ip
-	}
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
-}
@@ -49,7 +49,7 @@ func Error(w http.ResponseWriter, err error) {
 	var code int
 	var msg string
 
-	if e, ok := err.(*errorx.CodeError); ok {
+	if e, ok := errorx.FromError(err); ok {
 		code = e.GetCode()
 		msg = e.GetMsg()
 	} else {
@@ -246,7 +246,7 @@ func buildHttpError(err error) *HttpError {
 		}
 	}
 
-	if e, ok := err.(*errorx.CodeError); ok {
+	if e, ok := errorx.FromError(err); ok {
 		return &HttpError{
 			Code:        fmt.Sprintf("idrm.common.%d", e.GetCode()),
 			Description: e.GetMsg(),
@@ -4,164 +4,473 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"idrm/pkg/telemetry/httpclient"
+	"idrm/pkg/telemetry/mask"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/google/uuid"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
-var (
-	auditLogger *AuditLogger
-)
+// auditLogger 默认实例，包级函数（Log、Close 等）使用；nil 表示未启用
+var auditLogger *AuditLogger
 
-// AuditLogger 审计日志记录器
+// errRejected 审计服务永久拒绝该批次（4xx），重试无意义
+var errRejected = errors.New("audit batch rejected")
+
+// AuditLogger 审计日志记录器，方法对 nil 安全（未启用时不记录）
+// 事件按写入顺序编入哈希链，先写入预写文件（及本地审计文件）再返回，由单个发送协程批量投递；
+// 投递失败按指数退避持续重试，直到审计服务确认后才从预写文件中移除，进程重启后从未确认的事件继续投递
 type AuditLogger struct {
 	serviceName string
-	url         string
+	config      AuditConfig
 	client      *http.Client
-	buffer      []AuditLog
-	bufferSize  int
-	mu          sync.Mutex
-	closeChan   chan struct{}
+	queue       queue
+
+	// mu 保证哈希链顺序与写入顺序一致
+	mu    sync.Mutex
+	chain *chain
+	file  *fileSink
+
+	notify  chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+
+	// ctx 在 Close 超时后取消，中断进行中的发送和重试等待
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	closeOnce    sync.Once
+	registration metric.Registration
+
+	delivered atomic.Int64
+	retried   atomic.Int64
+	rejected  atomic.Int64
+	dropped   atomic.Int64
 }
 
-// Init 初始化审计日志
+// Stats 审计日志投递状态
+type Stats struct {
+	Backlog         int64 `json:"backlog"`           // 未确认投递的事件数
+	OldestPendingMs int64 `json:"oldest_pending_ms"` // 最早未确认事件已等待的时长(毫秒)
+	Delivered       int64 `json:"delivered"`         // 已确认投递的条数
+	Retried         int64 `json:"retried"`           // 批次重试次数
+	Rejected        int64 `json:"rejected"`          // 被审计服务拒绝（4xx）写入死信文件的条数
+	Dropped         int64 `json:"dropped"`           // 序列化或写入预写文件失败丢弃的条数
+}
+
+// Init 初始化审计日志并设为默认实例
 func Init(config AuditConfig, serviceName string) {
+	SetDefault(New(config, serviceName))
+}
+
+// New 创建审计日志记录器，未启用时返回 nil
+// 预写文件目录不可用时退化为内存队列，仍可投递但进程退出时未确认的事件丢失
+func New(config AuditConfig, serviceName string) *AuditLogger {
 	if !config.Enabled {
 		logx.Info("审计日志未启用")
-		return
+		return nil
+	}
+	config = withDefaults(config)
+
+	var q queue = &memQueue{}
+	if config.WALDir != "" {
+		w, err := openWAL(config.WALDir, config.WALSync)
+		if err != nil {
+			logx.Errorf("打开审计预写文件目录失败，使用内存队列 [dir=%s]: %v", config.WALDir, err)
+		} else {
+			q = w
+		}
 	}
 
-	auditLogger = &AuditLogger{
+	file, head := openChain(config, q)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &AuditLogger{
 		serviceName: serviceName,
-		url:         config.Url,
-		buffer:      make([]AuditLog, 0, config.Buffer),
-		bufferSize:  config.Buffer,
-		closeChan:   make(chan struct{}),
+		config:      config,
+		queue:       q,
+		chain:       newChain(config.HMACKey, head),
+		file:        file,
+		notify:      make(chan struct{}, 1),
+		closing:     make(chan struct{}),
+		done:        make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+		// 重试由 deliver 按退避策略处理，传输层不重试
 		client: &http.Client{
-			Timeout: 5 * time.Second,
+			Timeout:   5 * time.Second,
+			Transport: httpclient.NewTransport(nil, httpclient.Config{Name: "audit-service"}),
 		},
 	}
+	a.registerMetrics(config.Meter)
+
+	go a.run()
+
+	backlog, _ := q.backlog()
+	logx.Infof("审计日志初始化完成 [url=%s, buffer=%d, wal=%s, file=%s, backlog=%d, chain=%s, seq=%d]",
+		config.Url, config.Buffer, config.WALDir, config.File, backlog, a.chain.head.ChainID, head.Seq)
+	return a
+}
+
+// openChain 打开本地审计文件并确定哈希链的续接位置
+// 本地审计文件包含全部已写入的记录，优先以其最后一条续接；预写文件中的最后一条恰好是其下一条时
+// （写入预写文件后、写入本地文件前崩溃），先补写到本地文件。文件均为空时开始新链
+func openChain(config AuditConfig, q queue) (*fileSink, chainHead) {
+	walHead, err := parseHead(q.last())
+	if err != nil {
+		logx.Errorf("解析审计预写文件最后一条记录失败，开始新的哈希链: %v", err)
+	}
+	if config.File == "" {
+		return nil, walHead
+	}
 
-	// 启动定时刷新
-	go auditLogger.flushLoop()
+	file, last, err := openFileSink(config.File, config.WALSync)
+	if err != nil {
+		logx.Errorf("打开本地审计文件失败 [file=%s]: %v", config.File, err)
+		return nil, walHead
+	}
+	fileHead, err := parseHead(last)
+	if err != nil {
+		logx.Errorf("解析本地审计文件最后一条记录失败，开始新的哈希链: %v", err)
+		return file, chainHead{}
+	}
+	if fileHead.ChainID == "" {
+		return file, walHead
+	}
 
-	logx.Infof("审计日志初始化完成 [url=%s, buffer=%d]", config.Url, config.Buffer)
+	if walHead.ChainID == fileHead.ChainID && walHead.Seq == fileHead.Seq+1 {
+		var next struct {
+			PrevHash string `json:"prev_hash"`
+		}
+		if json.Unmarshal(q.last(), &next) == nil && next.PrevHash == fileHead.Hash {
+			if err := file.write(q.last()); err != nil {
+				logx.Errorf("补写本地审计文件失败: %v", err)
+			} else {
+				return file, walHead
+			}
+		}
+	}
+	return file, fileHead
 }
 
-// Log 记录审计日志
+// withDefaults 填充未设置的配置项
+func withDefaults(c AuditConfig) AuditConfig {
+	if c.Buffer <= 0 {
+		c.Buffer = 100
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.MaxBackoff < c.RetryBackoff {
+		c.MaxBackoff = time.Minute
+	}
+	if c.Meter == nil {
+		c.Meter = otel.GetMeterProvider().Meter("idrm/pkg/telemetry/audit")
+	}
+	return c
+}
+
+// Default 返回默认实例，未启用时为 nil
+func Default() *AuditLogger {
+	return auditLogger
+}
+
+// SetDefault 设置默认实例（nil 表示不记录）
+func SetDefault(a *AuditLogger) {
+	auditLogger = a
+}
+
+// Log 使用默认实例记录审计日志
 func Log(ctx context.Context, log AuditLog) {
-	if auditLogger == nil {
+	auditLogger.Log(ctx, log)
+}
+
+// Log 记录审计日志，写入预写文件后返回，由发送协程异步投递
+func (a *AuditLogger) Log(ctx context.Context, log AuditLog) {
+	if a == nil {
 		return
 	}
 
 	// 补充基础信息
+	if log.EventID == "" {
+		log.EventID = uuid.NewString()
+	}
 	log.Timestamp = time.Now()
-	log.ServiceName = auditLogger.serviceName
+	log.ServiceName = a.serviceName
+
+	// 变更前后数据及扩展字段按 mask 标签和键名规则脱敏
+	log.Before = mask.Value(log.Before)
+	log.After = mask.Value(log.After)
+	if log.Extra != nil {
+		log.Extra, _ = mask.Value(log.Extra).(map[string]interface{})
+	}
 
-	// 提取 TraceID
+	// 提取请求 ID、用户、租户及 TraceID
+	fillFromContext(ctx, &log)
 	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
 		log.TraceID = span.SpanContext().TraceID().String()
 	}
 
-	auditLogger.add(log)
+	a.add(log)
 }
 
-// LogWithDuration 记录审计日志（带执行时长）
+// LogWithDuration 使用默认实例记录审计日志（带执行时长）
 func LogWithDuration(ctx context.Context, log AuditLog, start time.Time) {
+	auditLogger.LogWithDuration(ctx, log, start)
+}
+
+// LogWithDuration 记录审计日志（带执行时长）
+func (a *AuditLogger) LogWithDuration(ctx context.Context, log AuditLog, start time.Time) {
 	log.Duration = time.Since(start).Milliseconds()
-	Log(ctx, log)
+	a.Log(ctx, log)
 }
 
-// add 添加审计日志到缓冲区
+// add 编入哈希链并写入队列和本地审计文件，积压达到一批时唤醒发送协程
+// 写入队列失败的事件不占用序号；写入本地文件失败时文件中出现缺口，由校验工具报告
 func (a *AuditLogger) add(log AuditLog) {
 	a.mu.Lock()
-	a.buffer = append(a.buffer, log)
-	shouldFlush := len(a.buffer) >= a.bufferSize
+	data, err := a.chain.seal(&log)
+	if err != nil {
+		a.mu.Unlock()
+		a.dropped.Add(1)
+		logx.Errorf("marshal audit log failed [event_id=%s]: %v", log.EventID, err)
+		return
+	}
+	if err := a.queue.append([]json.RawMessage{data}, log.Timestamp); err != nil {
+		a.mu.Unlock()
+		a.dropped.Add(1)
+		logx.Errorf("write audit log failed [event_id=%s]: %v", log.EventID, err)
+		return
+	}
+	a.chain.advance(log)
+	if a.file != nil {
+		if err := a.file.write(data); err != nil {
+			logx.Errorf("write audit file failed [event_id=%s, seq=%d]: %v", log.EventID, log.Seq, err)
+		}
+	}
 	a.mu.Unlock()
 
-	if shouldFlush {
-		a.flush()
+	if backlog, _ := a.queue.backlog(); backlog >= a.config.Buffer {
+		select {
+		case a.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run 发送协程：启动时先投递上次未确认的事件，之后按批次或定时投递，关闭时投递全部积压
+func (a *AuditLogger) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		if !a.flush() {
+			return
+		}
+		select {
+		case <-a.notify:
+		case <-ticker.C:
+		case <-a.closing:
+			a.flush()
+			return
+		}
 	}
 }
 
-// flush 发送审计日志
-func (a *AuditLogger) flush() {
-	a.mu.Lock()
-	if len(a.buffer) == 0 {
-		a.mu.Unlock()
-		return
+// flush 投递队列中的全部事件，Close 超时取消时返回 false
+func (a *AuditLogger) flush() bool {
+	for {
+		batch, err := a.queue.peek(a.config.Buffer)
+		if err != nil {
+			logx.Errorf("read audit wal failed: %v", err)
+			return a.sleep(a.config.MaxBackoff)
+		}
+		if len(batch) == 0 {
+			return true
+		}
+		if !a.deliver(batch) {
+			return false
+		}
+		if err := a.queue.commit(len(batch)); err != nil {
+			// 游标未持久化时重启后会重复投递，由审计服务按 event_id 去重
+			logx.Errorf("commit audit wal failed: %v", err)
+		}
 	}
+}
 
-	logs := make([]AuditLog, len(a.buffer))
-	copy(logs, a.buffer)
-	a.buffer = a.buffer[:0]
-	a.mu.Unlock()
+// deliver 投递一个批次，失败按指数退避持续重试直到审计服务确认；
+// 被永久拒绝的批次写入死信文件后视为已处理，避免阻塞后续事件
+func (a *AuditLogger) deliver(batch []json.RawMessage) bool {
+	backoff := a.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := a.send(batch)
+		if err == nil {
+			a.delivered.Add(int64(len(batch)))
+			return true
+		}
+		if errors.Is(err, errRejected) {
+			a.rejected.Add(int64(len(batch)))
+			a.queue.reject(batch)
+			logx.Errorf("audit logs rejected, moved to dead letter [count=%d]: %v", len(batch), err)
+			return true
+		}
+		if a.ctx.Err() != nil {
+			return false
+		}
 
-	// 异步发送
-	go a.send(logs)
+		// 审计服务长时间不可用时避免刷屏
+		if attempt == 1 || attempt%10 == 0 {
+			backlog, _ := a.queue.backlog()
+			logx.Errorf("send audit logs failed, retrying [attempt=%d, backlog=%d]: %v", attempt, backlog, err)
+		}
+		a.retried.Add(1)
+		if !a.sleep(jitter(backoff)) {
+			return false
+		}
+		backoff *= 2
+		if backoff > a.config.MaxBackoff {
+			backoff = a.config.MaxBackoff
+		}
+	}
 }
 
-// send 发送到审计服务
-func (a *AuditLogger) send(logs []AuditLog) {
+// send 发送到审计服务，2xx 视为确认；408、429 及 5xx 可重试，其余 4xx 返回 errRejected
+func (a *AuditLogger) send(batch []json.RawMessage) error {
 	data, err := json.Marshal(map[string]interface{}{
-		"audit_logs": logs,
+		"audit_logs": batch,
 	})
 	if err != nil {
-		logx.Errorf("marshal audit logs failed: %v", err)
-		return
+		return fmt.Errorf("%w: %v", errRejected, err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(a.ctx, http.MethodPost, a.config.Url, bytes.NewReader(data))
 	if err != nil {
-		logx.Errorf("create audit request failed: %v", err)
-		return
+		return fmt.Errorf("%w: %v", errRejected, err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := a.client.Do(req)
 	if err != nil {
-		logx.Errorf("send audit logs failed: %v", err)
-		return
+		return err
 	}
 	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		logx.Errorf("audit server returned status: %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return fmt.Errorf("audit server returned status: %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: status %d", errRejected, resp.StatusCode)
 	}
 }
 
-// flushLoop 定时刷新
-func (a *AuditLogger) flushLoop() {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+// sleep 等待重试间隔，Close 超时取消时提前返回 false
+func (a *AuditLogger) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	for {
-		select {
-		case <-ticker.C:
-			a.flush()
-		case <-a.closeChan:
-			a.flush()
-			return
-		}
+	select {
+	case <-timer.C:
+		return true
+	case <-a.ctx.Done():
+		return false
 	}
 }
 
-// Close 关闭审计日志
-func Close() {
-	if auditLogger != nil {
-		close(auditLogger.closeChan)
-		time.Sleep(100 * time.Millisecond) // 等待最后一次flush
+// jitter 在退避间隔基础上增加 ±20% 的随机抖动，避免多实例同时重试
+func jitter(d time.Duration) time.Duration {
+	delta := float64(d) * 0.2
+	return d + time.Duration(delta*(2*rand.Float64()-1))
+}
+
+// Stats 返回投递状态，未启用时为零值
+func (a *AuditLogger) Stats() Stats {
+	if a == nil {
+		return Stats{}
+	}
+	backlog, oldest := a.queue.backlog()
+	stats := Stats{
+		Backlog:   int64(backlog),
+		Delivered: a.delivered.Load(),
+		Retried:   a.retried.Load(),
+		Rejected:  a.rejected.Load(),
+		Dropped:   a.dropped.Load(),
+	}
+	if backlog > 0 {
+		stats.OldestPendingMs = time.Since(oldest).Milliseconds()
+	}
+	return stats
+}
+
+// GetStats 返回默认实例的投递状态
+func GetStats() Stats {
+	return auditLogger.Stats()
+}
+
+// Close 关闭默认实例
+func Close(ctx context.Context) error {
+	return auditLogger.Close(ctx)
+}
+
+// Close 投递全部积压事件后关闭，ctx 到期时中断投递并返回 ctx.Err()；
+// 未投递的事件保留在预写文件中，下次启动时继续投递
+func (a *AuditLogger) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+	a.closeOnce.Do(func() { close(a.closing) })
+
+	var err error
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		a.cancel()
+		<-a.done
+		err = ctx.Err()
+	}
+	a.cancel()
+
+	if a.registration != nil {
+		_ = a.registration.Unregister()
+	}
+	if backlog, _ := a.queue.backlog(); backlog > 0 {
+		logx.Errorf("审计日志关闭时仍有 %d 条未投递 [wal=%s]", backlog, a.config.WALDir)
+	}
+	if closeErr := a.queue.close(); err == nil {
+		err = closeErr
+	}
+	if a.file != nil {
+		a.mu.Lock()
+		if closeErr := a.file.close(); err == nil {
+			err = closeErr
+		}
+		a.mu.Unlock()
 	}
+	return err
 }
 
-// IsEnabled 是否启用审计日志
+// IsEnabled 默认实例是否启用审计日志
 func IsEnabled() bool {
 	return auditLogger != nil
 }
@@ -0,0 +1,328 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// auditServer 记录收到的事件 ID，前 fail 次请求返回 status
+type auditServer struct {
+	*httptest.Server
+	fail   atomic.Int64
+	status int
+
+	mu  sync.Mutex
+	ids []string
+}
+
+func newAuditServer(t *testing.T, fail int64, status int) *auditServer {
+	s := &auditServer{status: status}
+	s.fail.Store(fail)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.fail.Add(-1) >= 0 {
+			w.WriteHeader(s.status)
+			return
+		}
+		var body struct {
+			AuditLogs []AuditLog `json:"audit_logs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		s.mu.Lock()
+		for _, l := range body.AuditLogs {
+			s.ids = append(s.ids, l.EventID)
+		}
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *auditServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ids...)
+}
+
+func testConfig(url, dir string) AuditConfig {
+	return AuditConfig{
+		Enabled:       true,
+		Url:           url,
+		Buffer:        2,
+		FlushInterval: 10 * time.Millisecond,
+		WALDir:        dir,
+		RetryBackoff:  5 * time.Millisecond,
+		MaxBackoff:    20 * time.Millisecond,
+	}
+}
+
+func closeWithin(t *testing.T, a *AuditLogger, d time.Duration) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return a.Close(ctx)
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		fail         int64
+		status       int
+		wantReceived int
+		wantRejected int64
+	}{
+		{"直接成功", 0, http.StatusOK, 3, 0},
+		{"服务不可用时重试直到成功", 3, http.StatusServiceUnavailable, 3, 0},
+		{"限流时重试直到成功", 2, http.StatusTooManyRequests, 3, 0},
+		{"永久拒绝写入死信文件", 100, http.StatusBadRequest, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newAuditServer(t, tt.fail, tt.status)
+			dir := t.TempDir()
+			a := New(testConfig(srv.URL, dir), "idrm-test")
+			for i := 0; i < 3; i++ {
+				a.Log(context.Background(), AuditLog{Action: ActionCreate, Resource: ResourceCategory})
+			}
+			if err := closeWithin(t, a, 5*time.Second); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			ids := srv.received()
+			if len(ids) != tt.wantReceived {
+				t.Fatalf("received %d events, want %d", len(ids), tt.wantReceived)
+			}
+			seen := map[string]bool{}
+			for _, id := range ids {
+				if id == "" || seen[id] {
+					t.Errorf("event_id %q empty or duplicated", id)
+				}
+				seen[id] = true
+			}
+
+			stats := a.Stats()
+			if stats.Backlog != 0 || stats.Rejected != tt.wantRejected {
+				t.Errorf("Stats() = %+v, want backlog 0, rejected %d", stats, tt.wantRejected)
+			}
+			if tt.fail > 0 && tt.wantRejected == 0 && stats.Retried == 0 {
+				t.Error("Stats().Retried = 0, want retries")
+			}
+			if tt.wantRejected > 0 {
+				data, _ := os.ReadFile(filepath.Join(dir, walDeadLetter))
+				if n := strings.Count(string(data), "\n"); n != int(tt.wantRejected) {
+					t.Errorf("dead letter lines = %d, want %d", n, tt.wantRejected)
+				}
+			}
+		})
+	}
+}
+
+func TestWALReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	// 审计服务不可用：Close 超时后事件保留在预写文件中
+	down := newAuditServer(t, 1<<30, http.StatusServiceUnavailable)
+	a := New(testConfig(down.URL, dir), "idrm-test")
+	for i := 0; i < 5; i++ {
+		a.Log(context.Background(), AuditLog{Action: ActionDelete, Resource: ResourceUser})
+	}
+	if err := closeWithin(t, a, 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want deadline exceeded", err)
+	}
+	if stats := a.Stats(); stats.Backlog != 5 || stats.Delivered != 0 {
+		t.Fatalf("Stats() = %+v, want backlog 5", stats)
+	}
+	a.Log(context.Background(), AuditLog{Action: ActionDelete})
+	if stats := a.Stats(); stats.Dropped != 1 {
+		t.Errorf("Log after Close: Stats().Dropped = %d, want 1", stats.Dropped)
+	}
+
+	// 重启后从预写文件继续投递
+	up := newAuditServer(t, 0, http.StatusOK)
+	a = New(testConfig(up.URL, dir), "idrm-test")
+	if stats := a.Stats(); stats.Backlog != 5 {
+		t.Fatalf("replayed backlog = %d, want 5", stats.Backlog)
+	}
+	if err := closeWithin(t, a, 5*time.Second); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if ids := up.received(); len(ids) != 5 {
+		t.Fatalf("received %d events after restart, want 5", len(ids))
+	}
+
+	// 已确认的分段被删除（保留最新事件所在分段以续接哈希链），再次启动无积压
+	segments, _ := walSegments(dir)
+	if len(segments) != 2 {
+		t.Errorf("segments = %v, want the tail and the active one", segments)
+	}
+	a = New(testConfig(up.URL, dir), "idrm-test")
+	defer closeWithin(t, a, time.Second)
+	if stats := a.Stats(); stats.Backlog != 0 {
+		t.Errorf("backlog after delivery = %d, want 0", stats.Backlog)
+	}
+}
+
+func TestWALRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"完整记录", `{"event_id":"a","timestamp":"2024-01-01T00:00:00Z"}` + "\n" + `{"event_id":"b"}` + "\n", 2},
+		{"末尾写入一半", `{"event_id":"a"}` + "\n" + `{"event_id":"b","ti`, 1},
+		{"损坏记录跳过", `{"event_id":"a"}` + "\n" + "garbage\n" + `{"event_id":"c"}` + "\n", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "00000000000000000001"+walExt), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			w, err := openWAL(dir, false)
+			if err != nil {
+				t.Fatalf("openWAL() error = %v", err)
+			}
+			defer w.close()
+
+			records, err := w.peek(10)
+			if err != nil || len(records) != tt.want {
+				t.Fatalf("peek() = %d records, err = %v, want %d", len(records), err, tt.want)
+			}
+			for _, r := range records {
+				if !json.Valid(r) {
+					t.Errorf("record %s is not valid json", r)
+				}
+			}
+		})
+	}
+}
+
+func TestWALSegments(t *testing.T) {
+	dir := t.TempDir()
+	w, err := openWAL(dir, false)
+	if err != nil {
+		t.Fatalf("openWAL() error = %v", err)
+	}
+	w.maxSize = 1 // 每次追加后切换分段
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := w.append([]json.RawMessage{json.RawMessage(`{"event_id":"` + id + `"}`)}, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := w.peek(10)
+	if err != nil || len(records) != 3 {
+		t.Fatalf("peek() = %d records, err = %v, want 3", len(records), err)
+	}
+	if err := w.commit(2); err != nil {
+		t.Fatal(err)
+	}
+	w.close()
+
+	// 重新打开后只剩最后一条
+	w, err = openWAL(dir, false)
+	if err != nil {
+		t.Fatalf("openWAL() error = %v", err)
+	}
+	defer w.close()
+	records, _ = w.peek(10)
+	if len(records) != 1 || string(records[0]) != `{"event_id":"c"}` {
+		t.Errorf("records after reopen = %s, want [c]", records)
+	}
+}
+
+// failingSync 模拟 fsync 失败的分段文件（内容已写入）
+type failingSync struct {
+	segmentFile
+}
+
+func (f failingSync) Sync() error {
+	return errors.New("input/output error")
+}
+
+func TestWALSyncFailure(t *testing.T) {
+	dir := t.TempDir()
+	w, err := openWAL(dir, true)
+	if err != nil {
+		t.Fatalf("openWAL() error = %v", err)
+	}
+	w.active = failingSync{w.active}
+
+	// fsync 失败的事件已完整写入，保留待投递；后续事件写入新分段，偏移不受影响
+	for _, id := range []string{"a", "b", "c"} {
+		if err := w.append([]json.RawMessage{json.RawMessage(`{"event_id":"` + id + `"}`)}, time.Now()); err != nil {
+			t.Fatalf("append(%s) error = %v", id, err)
+		}
+	}
+	want := `[{"event_id":"a"} {"event_id":"b"} {"event_id":"c"}]`
+	records, err := w.peek(10)
+	if err != nil || fmt.Sprintf("%s", records) != want {
+		t.Fatalf("peek() = %s, err = %v, want %s", records, err, want)
+	}
+	if err := w.commit(1); err != nil {
+		t.Fatal(err)
+	}
+	w.close()
+
+	// 重新打开后未确认的事件各重放一次
+	w, err = openWAL(dir, true)
+	if err != nil {
+		t.Fatalf("openWAL() error = %v", err)
+	}
+	defer w.close()
+	records, _ = w.peek(10)
+	if got := fmt.Sprintf("%s", records); got != `[{"event_id":"b"} {"event_id":"c"}]` {
+		t.Errorf("records after reopen = %s, want [b c]", got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(context.Background())
+
+	down := newAuditServer(t, 1<<30, http.StatusServiceUnavailable)
+	config := testConfig(down.URL, t.TempDir())
+	config.Meter = mp.Meter("test")
+	a := New(config, "idrm-test")
+	defer closeWithin(t, a, 10*time.Millisecond)
+	a.Log(context.Background(), AuditLog{Action: ActionExport})
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				got[m.Name] = len(data.DataPoints) == 1 && data.DataPoints[0].Value == 1
+			case metricdata.Gauge[float64]:
+				got[m.Name] = len(data.DataPoints) == 1 && data.DataPoints[0].Value >= 0
+			}
+		}
+	}
+	for _, name := range []string{MetricBacklog, MetricOldestPendingAge} {
+		if !got[name] {
+			t.Errorf("metric %s missing or wrong, got %v", name, got)
+		}
+	}
+}
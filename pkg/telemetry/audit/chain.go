@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/google/uuid"
+)
+
+// hashPrefix 记录中 hash 字段的开头，hash 是最后一个字段
+const hashPrefix = `,"hash":"`
+
+// errNoHash 记录末尾没有 hash 字段
+var errNoHash = errors.New("audit record has no trailing hash")
+
+// chainHead 链的最后一条记录
+type chainHead struct {
+	ChainID string `json:"chain_id"`
+	Seq     uint64 `json:"seq"`
+	Hash    string `json:"hash"`
+}
+
+// chain 哈希链：为每条记录分配序号并链接上一条的哈希，调用方负责串行调用 seal/advance
+type chain struct {
+	key  []byte
+	head chainHead
+}
+
+// newChain 从上次的链尾继续，head 为空时开始新链
+func newChain(key string, head chainHead) *chain {
+	c := &chain{head: head}
+	if key != "" {
+		c.key = []byte(key)
+	}
+	if c.head.ChainID == "" {
+		c.head = chainHead{ChainID: uuid.NewString()}
+	}
+	return c
+}
+
+// seal 为记录分配序号和哈希并序列化，写入成功后调用 advance 推进链尾
+func (c *chain) seal(log *AuditLog) ([]byte, error) {
+	log.ChainID = c.head.ChainID
+	log.Seq = c.head.Seq + 1
+	log.PrevHash = c.head.Hash
+	log.Hash = ""
+
+	body, err := json.Marshal(log)
+	if err != nil {
+		return nil, err
+	}
+	log.Hash = sum(c.key, body)
+
+	record := make([]byte, 0, len(body)+len(hashPrefix)+len(log.Hash)+2)
+	record = append(record, body[:len(body)-1]...)
+	record = append(record, hashPrefix...)
+	record = append(record, log.Hash...)
+	record = append(record, `"}`...)
+	return record, nil
+}
+
+// advance 推进链尾到已写入的记录
+func (c *chain) advance(log AuditLog) {
+	c.head = chainHead{ChainID: log.ChainID, Seq: log.Seq, Hash: log.Hash}
+}
+
+// sum 计算哈希，key 非空时使用 HMAC-SHA256
+func sum(key, data []byte) string {
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// splitRecord 拆出参与哈希计算的原文和记录中的哈希
+func splitRecord(record []byte) (body []byte, hash string, err error) {
+	record = bytes.TrimSpace(record)
+	i := bytes.LastIndex(record, []byte(hashPrefix))
+	if i < 0 || !bytes.HasSuffix(record, []byte(`"}`)) {
+		return nil, "", errNoHash
+	}
+	hash = string(record[i+len(hashPrefix) : len(record)-2])
+	body = append(append([]byte(nil), record[:i]...), '}')
+	return body, hash, nil
+}
+
+// parseHead 解析记录的链信息，record 为空时返回零值
+func parseHead(record []byte) (chainHead, error) {
+	var head chainHead
+	if len(record) == 0 {
+		return head, nil
+	}
+	if err := json.Unmarshal(record, &head); err != nil {
+		return head, err
+	}
+	if head.ChainID == "" || head.Hash == "" {
+		return chainHead{}, fmt.Errorf("audit record has no chain: %s", record)
+	}
+	return head, nil
+}
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sealed 生成同一条链上的 n 条记录
+func sealed(t *testing.T, key string, n int) [][]byte {
+	t.Helper()
+	c := newChain(key, chainHead{})
+	records := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		log := AuditLog{EventID: string(rune('a' + i)), Action: ActionUpdate, After: map[string]interface{}{"amount": i}}
+		record, err := c.seal(&log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.advance(log)
+		records = append(records, record)
+	}
+	return records
+}
+
+func lines(records ...[]byte) string {
+	return string(bytes.Join(records, []byte("\n"))) + "\n"
+}
+
+// resealed 修改记录内容后用 key 重新计算哈希（模拟知道算法的篡改者）
+func resealed(t *testing.T, record []byte, key, old, new string) []byte {
+	t.Helper()
+	body, _, err := splitRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body = bytes.Replace(body, []byte(old), []byte(new), 1)
+	var k []byte
+	if key != "" {
+		k = []byte(key)
+	}
+	return append(append(body[:len(body)-1:len(body)-1], hashPrefix+sum(k, body)...), `"}`...)
+}
+
+func TestVerify(t *testing.T) {
+	const key = "secret"
+	r := sealed(t, key, 4)
+
+	tests := []struct {
+		name      string
+		key       string
+		input     string
+		wantKinds []string
+	}{
+		{"完整", key, lines(r...), nil},
+		{"修改内容", key, lines(r[0], bytes.Replace(r[1], []byte(`"amount":1`), []byte(`"amount":9`), 1), r[2], r[3]), []string{ProblemModified}},
+		{"删除记录", key, lines(r[0], r[1], r[3]), []string{ProblemGap}},
+		{"无密钥重算哈希", key, lines(r[0], resealed(t, r[1], "", `"amount":1`, `"amount":9`), r[2], r[3]), []string{ProblemModified, ProblemBroken}},
+		{"错误密钥", "other", lines(r[0]), []string{ProblemModified}},
+		{"缺少哈希", key, lines(r[0], []byte(`{"chain_id":"x","seq":2}`)), []string{ProblemInvalid}},
+		{"导出批次乱序且重复投递", key, `{"audit_logs":[` + string(bytes.Join([][]byte{r[2], r[0], r[1], r[2], r[3]}, []byte(","))) + `]}`, nil},
+		{"导出数组", key, "[\n  " + string(bytes.Join([][]byte{r[0], r[1]}, []byte(",\n  "))) + "\n]", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(tt.key)
+			if err := v.AddStream("test", strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("AddStream() error = %v", err)
+			}
+			_, problems := v.Result()
+
+			var kinds []string
+			for _, p := range problems {
+				kinds = append(kinds, p.Kind)
+			}
+			if strings.Join(kinds, ",") != strings.Join(tt.wantKinds, ",") {
+				t.Errorf("problems = %v, want kinds %v", problems, tt.wantKinds)
+			}
+		})
+	}
+}
+
+func TestChainFile(t *testing.T) {
+	srv := newAuditServer(t, 0, http.StatusOK)
+	dir := t.TempDir()
+	config := testConfig(srv.URL, filepath.Join(dir, "wal"))
+	config.File = filepath.Join(dir, "audit.jsonl")
+	config.HMACKey = "secret"
+
+	logN := func(n int) {
+		a := New(config, "idrm-test")
+		for i := 0; i < n; i++ {
+			a.Log(context.Background(), AuditLog{Action: ActionCreate, Resource: ResourceRole})
+		}
+		if err := closeWithin(t, a, 5*time.Second); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	// 重启后续接同一条链
+	logN(3)
+	logN(2)
+
+	// 模拟写入预写文件后、写入本地文件前崩溃：本地文件缺少最后一条，重启时补写
+	data, err := os.ReadFile(config.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trimmed := data[:bytes.LastIndexByte(data[:len(data)-1], '\n')+1]
+	if err := os.WriteFile(config.File, trimmed, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	logN(1)
+
+	file, err := os.Open(config.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	v := NewVerifier(config.HMACKey)
+	if err := v.AddStream(config.File, file); err != nil {
+		t.Fatal(err)
+	}
+	chains, problems := v.Result()
+	if len(problems) != 0 {
+		t.Errorf("problems = %v", problems)
+	}
+	if len(chains) != 1 || chains[0].Records != 6 || chains[0].FirstSeq != 1 || chains[0].LastSeq != 6 {
+		t.Errorf("chains = %+v, want one chain with seq 1-6", chains)
+	}
+	if got := len(srv.received()); got != 6 {
+		t.Errorf("received %d events, want 6", got)
+	}
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileSink 本地只追加审计文件（JSON Lines），与投递到审计服务的记录逐字节相同，可用 cmd/auditverify 校验
+type fileSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	sync   bool
+	closed bool
+}
+
+// openFileSink 以只追加方式打开审计文件，返回文件中最后一条完整记录（用于续接哈希链）
+// 崩溃时写入一半的行不截断，补换行后保留，由校验工具报告为无效记录
+func openFileSink(path string, sync bool) (*fileSink, []byte, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, nil, err
+	}
+	last, torn, err := lastLine(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, nil, err
+	}
+	if torn {
+		if _, err := file.Write([]byte{'\n'}); err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+	}
+	return &fileSink{file: file, sync: sync}, last, nil
+}
+
+// write 追加一条记录
+func (s *fileSink) write(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line := make([]byte, 0, len(record)+1)
+	line = append(append(line, record...), '\n')
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	if s.sync {
+		return s.file.Sync()
+	}
+	return nil
+}
+
+func (s *fileSink) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.file.Close()
+}
+
+// lastLine 从文件末尾向前读取最后一条以换行结尾的完整行，文件不存在或为空时返回 nil
+// 末尾不完整的行（崩溃时写入一半）被忽略，torn 表示文件不以换行结尾
+func lastLine(path string) (line []byte, torn bool, err error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	if info.Size() > 0 {
+		tail := make([]byte, 1)
+		if _, err := file.ReadAt(tail, info.Size()-1); err != nil {
+			return nil, false, err
+		}
+		torn = tail[0] != '\n'
+	}
+
+	size := info.Size()
+	for chunk := int64(64 << 10); ; chunk *= 2 {
+		start := size - chunk
+		if start < 0 {
+			start = 0
+		}
+		data := make([]byte, size-start)
+		if _, err := file.ReadAt(data, start); err != nil && err != io.EOF {
+			return nil, torn, err
+		}
+
+		// 去掉末尾不完整的行
+		end := bytes.LastIndexByte(data, '\n')
+		if end < 0 {
+			if start == 0 {
+				return nil, torn, nil
+			}
+			continue
+		}
+		data = data[:end]
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			return data[i+1:], torn, nil
+		}
+		if start == 0 {
+			return data, torn, nil
+		}
+	}
+}
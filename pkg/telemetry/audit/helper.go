@@ -4,18 +4,31 @@ import (
 	"context"
 	"net/http"
 	"time"
+
+	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/trace"
 )
 
+// helperKey 上下文中 Helper 的键
+type helperKey struct{}
+
 // Helper 审计日志辅助结构
 type Helper struct {
+	logger    *AuditLogger
 	ctx       context.Context
 	log       AuditLog
 	startTime time.Time
 }
 
-// NewHelper 创建审计日志辅助器
+// NewHelper 创建使用默认实例的审计日志辅助器
 func NewHelper(ctx context.Context) *Helper {
+	return auditLogger.NewHelper(ctx)
+}
+
+// NewHelper 创建审计日志辅助器
+func (a *AuditLogger) NewHelper(ctx context.Context) *Helper {
 	return &Helper{
+		logger:    a,
 		ctx:       ctx,
 		log:       AuditLog{},
 		startTime: time.Now(),
@@ -47,16 +60,35 @@ func (h *Helper) WithIP(ip string) *Helper {
 	return h
 }
 
-// WithRequest 设置请求信息
+// WithTenant 设置租户
+func (h *Helper) WithTenant(tenantID string) *Helper {
+	h.log.TenantID = tenantID
+	return h
+}
+
+// WithRequest 设置请求信息：Method、Path、客户端 IP，以及请求上下文中的请求 ID、已认证用户和租户
 func (h *Helper) WithRequest(req *http.Request) *Helper {
 	if req != nil {
 		h.log.Method = req.Method
 		h.log.Path = req.URL.Path
-		h.log.IP = req.RemoteAddr
+		h.log.IP = trace.ClientIP(req)
+		fillFromContext(req.Context(), &h.log)
 	}
 	return h
 }
 
+// WithRoute 设置路由模板
+func (h *Helper) WithRoute(route string) *Helper {
+	h.log.Route = route
+	return h
+}
+
+// WithStatus 设置 HTTP 状态码
+func (h *Helper) WithStatus(status int) *Helper {
+	h.log.Status = status
+	return h
+}
+
 // WithBefore 设置操作前数据
 func (h *Helper) WithBefore(before interface{}) *Helper {
 	h.log.Before = before
@@ -81,7 +113,7 @@ func (h *Helper) WithExtra(key string, value interface{}) *Helper {
 // Success 记录成功的审计日志
 func (h *Helper) Success() {
 	h.log.Success = true
-	LogWithDuration(h.ctx, h.log, h.startTime)
+	h.logger.LogWithDuration(h.ctx, h.log, h.startTime)
 }
 
 // Fail 记录失败的审计日志
@@ -90,7 +122,7 @@ func (h *Helper) Fail(err error) {
 	if err != nil {
 		h.log.Error = err.Error()
 	}
-	LogWithDuration(h.ctx, h.log, h.startTime)
+	h.logger.LogWithDuration(h.ctx, h.log, h.startTime)
 }
 
 // SuccessOrFail 根据错误自动判断成功或失败
@@ -101,3 +133,35 @@ func (h *Helper) SuccessOrFail(err error) {
 		h.Success()
 	}
 }
+
+// ContextWithHelper 将 Helper 放入上下文，供后续处理器补充审计信息（见 middleware.Audit）
+func ContextWithHelper(ctx context.Context, h *Helper) context.Context {
+	return context.WithValue(ctx, helperKey{}, h)
+}
+
+// HelperFromContext 返回上下文中的 Helper，不存在时返回不记录的 Helper（可安全链式调用）
+//
+//	audit.HelperFromContext(l.ctx).WithBefore(old).WithExtra("category_id", id)
+func HelperFromContext(ctx context.Context) *Helper {
+	if h, ok := ctx.Value(helperKey{}).(*Helper); ok {
+		return h
+	}
+	return (*AuditLogger)(nil).NewHelper(ctx)
+}
+
+// fillFromContext 补充上下文中的请求 ID、用户和租户（log.WithUser 等写入，其次为 Baggage），已设置的字段不覆盖
+func fillFromContext(ctx context.Context, l *AuditLog) {
+	if l.RequestID == "" {
+		l.RequestID = log.RequestID(ctx)
+	}
+	if l.UserID == "" {
+		if l.UserID = log.UserID(ctx); l.UserID == "" {
+			l.UserID = trace.UserID(ctx)
+		}
+	}
+	if l.TenantID == "" {
+		if l.TenantID = log.TenantID(ctx); l.TenantID == "" {
+			l.TenantID = trace.TenantID(ctx)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/trace"
+)
+
+func TestHelperWithRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		ctx        func(context.Context) context.Context
+		wantIP     string
+		wantUser   string
+		wantTenant string
+	}{
+		{"连接地址", nil, nil, "192.0.2.1", "", ""},
+		{"未配置受信代理时忽略 X-Forwarded-For", map[string]string{"X-Forwarded-For": "10.0.0.1, 10.0.0.2"}, nil, "192.0.2.1", "", ""},
+		{"未配置受信代理时忽略 X-Real-IP", map[string]string{"X-Real-IP": "10.0.0.3"}, nil, "192.0.2.1", "", ""},
+		{"日志上下文中的用户和租户", nil, func(ctx context.Context) context.Context {
+			return log.WithTenant(log.WithUser(ctx, "u1"), "t1")
+		}, "192.0.2.1", "u1", "t1"},
+		{"Baggage 中的用户和租户", nil, func(ctx context.Context) context.Context {
+			return trace.WithTenantID(trace.WithUserID(ctx, "u2"), "t2")
+		}, "192.0.2.1", "u2", "t2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/category", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			ctx := log.WithRequestID(req.Context(), "req-1")
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+			req = req.WithContext(ctx)
+
+			got := (*AuditLogger)(nil).NewHelper(ctx).WithRequest(req).log
+			if got.IP != tt.wantIP || got.UserID != tt.wantUser || got.TenantID != tt.wantTenant ||
+				got.RequestID != "req-1" || got.Method != "POST" || got.Path != "/api/v1/category" {
+				t.Errorf("log = %+v", got)
+			}
+		})
+	}
+}
+
+func TestHelperFromContext(t *testing.T) {
+	// 上下文中没有 Helper 时返回不记录的 Helper，可安全调用
+	HelperFromContext(context.Background()).WithExtra("k", "v").Success()
+
+	h := (*AuditLogger)(nil).NewHelper(context.Background())
+	ctx := ContextWithHelper(context.Background(), h)
+	HelperFromContext(ctx).WithBefore("old")
+	if h.log.Before != "old" {
+		t.Errorf("Before = %v, want the helper from context to be updated", h.log.Before)
+	}
+}
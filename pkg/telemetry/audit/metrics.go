@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 审计投递指标
+const (
+	MetricBacklog          = "audit.backlog"            // 未确认投递的事件数
+	MetricOldestPendingAge = "audit.oldest_pending_age" // 最早未确认事件已等待的时长(秒)
+)
+
+// registerMetrics 注册积压指标，采集时读取队列状态；Close 时注销
+func (a *AuditLogger) registerMetrics(meter metric.Meter) {
+	backlog, err1 := meter.Int64ObservableGauge(MetricBacklog,
+		metric.WithDescription("未确认投递到审计服务的事件数"), metric.WithUnit("{event}"))
+	age, err2 := meter.Float64ObservableGauge(MetricOldestPendingAge,
+		metric.WithDescription("最早未确认投递的事件已等待的时长"), metric.WithUnit("s"))
+	if err := errors.Join(err1, err2); err != nil {
+		logx.Errorf("注册审计日志指标失败: %v", err)
+		return
+	}
+
+	reg, err := meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		n, oldest := a.queue.backlog()
+		o.ObserveInt64(backlog, int64(n))
+		if n > 0 {
+			o.ObserveFloat64(age, time.Since(oldest).Seconds())
+		} else {
+			o.ObserveFloat64(age, 0)
+		}
+		return nil
+	}, backlog, age)
+	if err != nil {
+		logx.Errorf("注册审计日志指标失败: %v", err)
+		return
+	}
+	a.registration = reg
+}
@@ -1,13 +1,23 @@
 package audit
 
-import "time"
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
 
 // AuditLog 审计日志结构
 type AuditLog struct {
 	// 基础信息
+	EventID     string    `json:"event_id"` // 事件唯一 ID，重试可能重复投递，审计服务据此去重
 	Timestamp   time.Time `json:"timestamp"`
 	ServiceName string    `json:"service_name"`
 
+	// 防篡改链：同一链内 Seq 连续递增，PrevHash 为上一条的 Hash
+	ChainID  string `json:"chain_id"`
+	Seq      uint64 `json:"seq"`
+	PrevHash string `json:"prev_hash"` // 链的第一条为空
+
 	// 操作信息
 	Action   string `json:"action"`   // 操作类型：create/update/delete/query/login/logout
 	Resource string `json:"resource"` // 资源类型：category/user/order/config
@@ -15,12 +25,15 @@ type AuditLog struct {
 	// 用户信息
 	UserID   string `json:"user_id,omitempty"`
 	Username string `json:"username,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
 	IP       string `json:"ip,omitempty"`
 
 	// 请求信息
-	Method  string `json:"method,omitempty"`   // HTTP Method
-	Path    string `json:"path,omitempty"`     // 请求路径
-	TraceID string `json:"trace_id,omitempty"` // 链路ID
+	Method    string `json:"method,omitempty"`     // HTTP Method
+	Path      string `json:"path,omitempty"`       // 请求路径
+	Route     string `json:"route,omitempty"`      // 路由模板，如 /api/v1/category/:id
+	RequestID string `json:"request_id,omitempty"` // 请求ID
+	TraceID   string `json:"trace_id,omitempty"`   // 链路ID
 
 	// 操作详情
 	Before interface{} `json:"before,omitempty"` // 操作前数据
@@ -28,18 +41,34 @@ type AuditLog struct {
 
 	// 结果
 	Success  bool   `json:"success"`
+	Status   int    `json:"status,omitempty"` // HTTP 状态码
 	Error    string `json:"error,omitempty"`
 	Duration int64  `json:"duration,omitempty"` // 执行时长(ms)
 
 	// 扩展字段
 	Extra map[string]interface{} `json:"extra,omitempty"`
+
+	// Hash 本条记录的哈希（SHA-256，配置 HMACKey 时为 HMAC-SHA256），必须是最后一个字段：
+	// 计算时不含该字段，校验时去掉末尾的 hash 字段即为参与计算的原文
+	Hash string `json:"hash,omitempty"`
 }
 
 // AuditConfig 审计日志配置
 type AuditConfig struct {
 	Enabled bool
 	Url     string
-	Buffer  int
+	Buffer  int // 批量发送条数
+
+	FlushInterval time.Duration // 不足一批时的定时发送间隔，默认 1s
+	WALDir        string        // 预写文件目录，为空时仅缓存在内存（进程退出时未投递的事件丢失）
+	WALSync       bool          // 每次写入预写文件后 fsync
+	RetryBackoff  time.Duration // 首次重试间隔，之后指数递增，默认 500ms
+	MaxBackoff    time.Duration // 重试间隔上限，默认 1m
+
+	HMACKey string // 哈希链签名密钥，为空时使用 SHA-256（只能发现误改，无法防止重算哈希）
+	File    string // 本地只追加审计文件（JSON Lines），为空时不写
+
+	Meter metric.Meter // 积压指标的 Meter，为 nil 时使用 otel 全局 MeterProvider
 }
 
 // 常用操作类型
@@ -60,4 +89,5 @@ const (
 	ResourceUser     = "user"
 	ResourceRole     = "role"
 	ResourceConfig   = "config"
+	ResourceLogLevel = "log_level"
 )
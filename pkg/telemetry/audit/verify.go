@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// 校验问题类型
+const (
+	ProblemInvalid    = "invalid"     // 无法解析或缺少链字段
+	ProblemModified   = "modified"    // 哈希与内容不符（内容被修改）
+	ProblemBroken     = "broken"      // prev_hash 与上一条的 hash 不符（上一条被修改或替换）
+	ProblemGap        = "gap"         // 序号不连续（记录被删除）
+	ProblemConflict   = "conflict"    // 同一序号出现内容不同的记录
+	ProblemBadGenesis = "bad_genesis" // 链的第一条 prev_hash 不为空
+)
+
+// Problem 校验发现的问题
+type Problem struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location"` // 文件及记录序号
+	ChainID  string `json:"chain_id,omitempty"`
+	Seq      uint64 `json:"seq,omitempty"`
+	Detail   string `json:"detail"`
+}
+
+func (p Problem) String() string {
+	if p.ChainID == "" {
+		return fmt.Sprintf("%s: %s: %s", p.Location, p.Kind, p.Detail)
+	}
+	return fmt.Sprintf("%s: %s: chain=%s seq=%d: %s", p.Location, p.Kind, p.ChainID, p.Seq, p.Detail)
+}
+
+// ChainSummary 单条链的校验范围
+type ChainSummary struct {
+	ChainID  string
+	Records  int
+	FirstSeq uint64
+	LastSeq  uint64
+}
+
+// verifyEntry 已校验哈希的记录
+type verifyEntry struct {
+	location string
+	seq      uint64
+	prevHash string
+	hash     string
+}
+
+// Verifier 哈希链校验器，记录可按任意顺序加入（如审计服务导出的批次），Result 时按链和序号检查连续性
+// 只能发现范围内的修改、删除和断链；链尾被截断需与审计服务或监控中的最新序号对比
+type Verifier struct {
+	key      []byte
+	chains   map[string][]verifyEntry
+	problems []Problem
+}
+
+// NewVerifier 创建校验器，key 为空时按 SHA-256 校验
+func NewVerifier(key string) *Verifier {
+	v := &Verifier{chains: map[string][]verifyEntry{}}
+	if key != "" {
+		v.key = []byte(key)
+	}
+	return v
+}
+
+// Add 校验一条记录的哈希并加入链
+func (v *Verifier) Add(location string, record []byte) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, record); err != nil {
+		v.problems = append(v.problems, Problem{Kind: ProblemInvalid, Location: location, Detail: err.Error()})
+		return
+	}
+
+	var log struct {
+		ChainID  string `json:"chain_id"`
+		Seq      uint64 `json:"seq"`
+		PrevHash string `json:"prev_hash"`
+	}
+	body, hash, err := splitRecord(compact.Bytes())
+	if err == nil {
+		err = json.Unmarshal(body, &log)
+	}
+	if err == nil && (log.ChainID == "" || log.Seq == 0) {
+		err = fmt.Errorf("missing chain_id or seq")
+	}
+	if err != nil {
+		v.problems = append(v.problems, Problem{Kind: ProblemInvalid, Location: location, Detail: err.Error()})
+		return
+	}
+
+	if want := sum(v.key, body); want != hash {
+		v.problems = append(v.problems, Problem{Kind: ProblemModified, Location: location, ChainID: log.ChainID, Seq: log.Seq,
+			Detail: "hash does not match content"})
+	}
+	v.chains[log.ChainID] = append(v.chains[log.ChainID], verifyEntry{location, log.Seq, log.PrevHash, hash})
+}
+
+// AddStream 读取审计文件（JSON Lines，按行号定位）或导出批次（{"audit_logs":[...]} 或数组，按序号定位），逐条加入
+func (v *Verifier) AddStream(name string, r io.Reader) error {
+	reader := bufio.NewReaderSize(r, 64<<10)
+	first, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	// 第一行是带链字段的完整记录时按 JSON Lines 处理
+	var head struct {
+		ChainID string `json:"chain_id"`
+	}
+	trimmed := bytes.TrimSpace(first)
+	if len(trimmed) > 0 && trimmed[0] == '{' && json.Unmarshal(trimmed, &head) == nil && head.ChainID != "" {
+		for line := 1; ; line++ {
+			if record := bytes.TrimSpace(first); len(record) > 0 {
+				v.Add(fmt.Sprintf("%s:%d", name, line), record)
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if first, err = reader.ReadBytes('\n'); err != nil && err != io.EOF {
+				return err
+			}
+		}
+	}
+	return v.addBatches(name, io.MultiReader(bytes.NewReader(first), reader))
+}
+
+// addBatches 读取一个或多个导出批次
+func (v *Verifier) addBatches(name string, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	n := 0
+	for {
+		var value json.RawMessage
+		if err := dec.Decode(&value); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: after record %d: %w", name, n, err)
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(value, &batch); err != nil {
+			var export struct {
+				AuditLogs []json.RawMessage `json:"audit_logs"`
+			}
+			if err := json.Unmarshal(value, &export); err != nil || export.AuditLogs == nil {
+				return fmt.Errorf("%s: after record %d: not an audit export batch", name, n)
+			}
+			batch = export.AuditLogs
+		}
+		for _, record := range batch {
+			n++
+			v.Add(fmt.Sprintf("%s#%d", name, n), record)
+		}
+	}
+}
+
+// Result 检查各链序号连续性和哈希链接，返回链范围及全部问题
+func (v *Verifier) Result() ([]ChainSummary, []Problem) {
+	problems := append([]Problem(nil), v.problems...)
+	ids := make([]string, 0, len(v.chains))
+	for id := range v.chains {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	summaries := make([]ChainSummary, 0, len(ids))
+	for _, id := range ids {
+		entries := v.chains[id]
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+
+		problem := func(kind string, e verifyEntry, format string, args ...interface{}) {
+			problems = append(problems, Problem{Kind: kind, Location: e.location, ChainID: id, Seq: e.seq, Detail: fmt.Sprintf(format, args...)})
+		}
+		if first := entries[0]; first.seq == 1 && first.prevHash != "" {
+			problem(ProblemBadGenesis, first, "first record of chain has prev_hash")
+		}
+
+		records := 1
+		for i := 1; i < len(entries); i++ {
+			prev, cur := entries[i-1], entries[i]
+			switch {
+			case cur.seq == prev.seq:
+				// 重复投递的同一记录不算问题
+				if cur.hash != prev.hash {
+					problem(ProblemConflict, cur, "differs from record at %s", prev.location)
+				}
+				continue
+			case cur.seq > prev.seq+1:
+				if cur.seq == prev.seq+2 {
+					problem(ProblemGap, cur, "missing seq %d", prev.seq+1)
+				} else {
+					problem(ProblemGap, cur, "missing seq %d-%d", prev.seq+1, cur.seq-1)
+				}
+			case cur.prevHash != prev.hash:
+				problem(ProblemBroken, cur, "prev_hash does not match record at %s", prev.location)
+			}
+			records++
+		}
+		summaries = append(summaries, ChainSummary{ChainID: id, Records: records, FirstSeq: entries[0].seq, LastSeq: entries[len(entries)-1].seq})
+	}
+	return summaries, problems
+}
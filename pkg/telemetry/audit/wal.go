@@ -0,0 +1,459 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	walExt         = ".wal"
+	walCursorFile  = "cursor.json"
+	walDeadLetter  = "dead-letter.jsonl"
+	walSegmentSize = 16 << 20 // 单个分段文件上限，超过后切换新分段
+)
+
+// errQueueClosed 记录器已关闭
+var errQueueClosed = errors.New("audit queue closed")
+
+// queue 待投递审计事件队列，事件为序列化后的 JSON（重放时原样发送）
+// 由单个发送协程按 peek -> 投递 -> commit 顺序消费
+type queue interface {
+	append(records []json.RawMessage, now time.Time) error
+	peek(n int) ([]json.RawMessage, error) // 返回最早的 n 条未确认事件
+	commit(n int) error                    // 确认最早的 n 条事件已投递
+	reject(records []json.RawMessage)      // 保存被审计服务永久拒绝的事件
+	backlog() (int, time.Time)             // 未确认条数及最早一条的记录时间
+	last() json.RawMessage                 // 打开时已有的最后一条事件（含已确认的），用于续接哈希链
+	close() error
+}
+
+// memQueue 内存队列，未配置 WALDir 时使用，进程退出时未投递的事件丢失
+type memQueue struct {
+	mu      sync.Mutex
+	records []json.RawMessage
+	times   []time.Time
+	closed  bool
+}
+
+func (q *memQueue) append(records []json.RawMessage, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return errQueueClosed
+	}
+	for _, r := range records {
+		q.records = append(q.records, r)
+		q.times = append(q.times, now)
+	}
+	return nil
+}
+
+func (q *memQueue) peek(n int) ([]json.RawMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n > len(q.records) {
+		n = len(q.records)
+	}
+	return append([]json.RawMessage(nil), q.records[:n]...), nil
+}
+
+func (q *memQueue) commit(n int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.records = q.records[n:]
+	q.times = q.times[n:]
+	return nil
+}
+
+func (q *memQueue) reject(records []json.RawMessage) {
+	for _, r := range records {
+		logx.Errorf("审计事件被拒绝且未配置 WALDir，已丢弃: %s", r)
+	}
+}
+
+func (q *memQueue) backlog() (int, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.records) == 0 {
+		return 0, time.Time{}
+	}
+	return len(q.records), q.times[0]
+}
+
+func (q *memQueue) last() json.RawMessage {
+	return nil
+}
+
+func (q *memQueue) close() error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	return nil
+}
+
+// segmentFile 活动分段文件（测试中可替换以模拟写入失败）
+type segmentFile interface {
+	io.Writer
+	Sync() error
+	Close() error
+}
+
+// walRecord 未确认事件在分段文件中的位置
+type walRecord struct {
+	segment int64
+	offset  int64
+	length  int64 // 含换行符
+	time    time.Time
+}
+
+// walCursor 投递游标：之前的事件均已被审计服务确认
+type walCursor struct {
+	Segment int64 `json:"segment"`
+	Offset  int64 `json:"offset"`
+}
+
+// wal 预写文件队列
+// 事件按 JSON Lines 追加到分段文件（可选 fsync）后才返回，投递确认后推进游标并删除已全部确认的分段；
+// 启动时从游标位置重放未确认事件，崩溃前写入一半的行被忽略
+type wal struct {
+	dir     string
+	sync    bool
+	maxSize int64
+
+	mu       sync.Mutex
+	active   segmentFile
+	activeID int64
+	size     int64
+	segments []int64 // 磁盘上的分段，升序
+	pending  []walRecord
+	cursor   walCursor
+	closed   bool
+
+	// tail 打开时最后一条完整事件，tailSegment 为最新事件所在分段，该分段即使已全部确认也保留，重启后可续接哈希链
+	tail        []byte
+	tailSegment int64
+}
+
+// openWAL 打开预写文件目录，加载游标和未确认事件，并创建新的活动分段
+func openWAL(dir string, sync bool) (*wal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	w := &wal{dir: dir, sync: sync, maxSize: walSegmentSize}
+
+	if data, err := os.ReadFile(filepath.Join(dir, walCursorFile)); err == nil {
+		if err := json.Unmarshal(data, &w.cursor); err != nil {
+			return nil, fmt.Errorf("audit wal cursor: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// 新分段编号总是大于游标所在分段，避免重启后被当作已确认的分段删除
+	w.activeID = w.cursor.Segment
+	segments, err := walSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range segments {
+		if info, err := os.Stat(w.segmentPath(id)); id < w.cursor.Segment || (err == nil && info.Size() == 0) {
+			// 已确认的分段及上次启动后未写入的空分段
+			_ = os.Remove(w.segmentPath(id))
+			continue
+		}
+		if err := w.load(id); err != nil {
+			return nil, err
+		}
+		w.segments = append(w.segments, id)
+		w.activeID = id
+	}
+
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// load 扫描分段文件，将游标之后的完整行加入未确认列表，并记录最后一条完整行
+func (w *wal) load(id int64) error {
+	file, err := os.Open(w.segmentPath(id))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// 末尾不完整的行是崩溃时写入一半的事件，未曾确认给调用方
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		length := int64(len(line))
+		w.tail, w.tailSegment = line[:length-1], id
+		if id == w.cursor.Segment && offset < w.cursor.Offset {
+			offset += length
+			continue
+		}
+
+		var head struct {
+			Timestamp time.Time `json:"timestamp"`
+		}
+		if err := json.Unmarshal(line, &head); err != nil {
+			logx.Errorf("审计预写文件 %s 偏移 %d 的记录损坏，已跳过: %v", w.segmentPath(id), offset, err)
+		} else {
+			w.pending = append(w.pending, walRecord{segment: id, offset: offset, length: length, time: head.Timestamp})
+		}
+		offset += length
+	}
+}
+
+// rotate 关闭当前分段并创建下一个分段，调用方持有 mu 或处于初始化阶段
+func (w *wal) rotate() error {
+	if w.active != nil {
+		if err := w.active.Close(); err != nil {
+			return err
+		}
+	}
+	id := w.activeID + 1
+	file, err := os.OpenFile(w.segmentPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.active, w.activeID, w.size = file, id, 0
+	w.segments = append(w.segments, id)
+	return nil
+}
+
+func (w *wal) append(records []json.RawMessage, now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errQueueClosed
+	}
+	if w.size >= w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	added := make([]walRecord, 0, len(records))
+	for _, r := range records {
+		added = append(added, walRecord{segment: w.activeID, offset: w.size + int64(buf.Len()), length: int64(len(r)) + 1, time: now})
+		buf.Write(r)
+		buf.WriteByte('\n')
+	}
+
+	n, err := w.active.Write(buf.Bytes())
+	w.size += int64(n)
+	if err != nil {
+		// 部分写入的内容在下次启动时作为不完整的行忽略；后续写入从新分段开始，避免拼接到半行之后
+		if n > 0 {
+			_ = w.rotate()
+		}
+		return err
+	}
+	w.pending = append(w.pending, added...)
+	w.tailSegment = w.activeID
+
+	// 内容已完整写入，fsync 失败时事件仍保留待投递（重启后也会重放），不算丢弃；
+	// 失败后该文件的脏页状态不可信，后续写入切换到新分段
+	if w.sync {
+		if err := w.active.Sync(); err != nil {
+			logx.Errorf("审计预写文件 fsync 失败，切换新分段: %v", err)
+			if err := w.rotate(); err != nil {
+				logx.Errorf("审计预写文件切换分段失败: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (w *wal) peek(n int) ([]json.RawMessage, error) {
+	w.mu.Lock()
+	if n > len(w.pending) {
+		n = len(w.pending)
+	}
+	records := append([]walRecord(nil), w.pending[:n]...)
+	w.mu.Unlock()
+
+	// 已写入的区域不再修改，可在锁外读取；同一分段内的连续事件一次读出
+	result := make([]json.RawMessage, 0, len(records))
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && records[end].segment == records[start].segment {
+			end++
+		}
+		first, last := records[start], records[end-1]
+		data, err := readAt(w.segmentPath(first.segment), first.offset, last.offset+last.length-first.offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records[start:end] {
+			at := r.offset - first.offset
+			result = append(result, json.RawMessage(data[at:at+r.length-1]))
+		}
+		start = end
+	}
+	return result, nil
+}
+
+func (w *wal) commit(n int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	last := w.pending[n-1]
+	w.pending = w.pending[n:]
+	w.cursor = walCursor{Segment: last.segment, Offset: last.offset + last.length}
+	if err := w.saveCursor(); err != nil {
+		return err
+	}
+
+	// 删除已全部确认的非活动分段，保留最新事件所在分段
+	keep := w.tailSegment
+	if len(w.pending) > 0 {
+		keep = w.pending[0].segment
+	}
+	for len(w.segments) > 0 && w.segments[0] < keep {
+		if err := os.Remove(w.segmentPath(w.segments[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		w.segments = w.segments[1:]
+	}
+	return nil
+}
+
+// saveCursor 先写临时文件再重命名，保证游标文件始终完整
+func (w *wal) saveCursor() error {
+	data, err := json.Marshal(w.cursor)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(w.dir, walCursorFile)
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if w.sync {
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// reject 将被永久拒绝的事件追加到死信文件，供人工核查后补录
+func (w *wal) reject(records []json.RawMessage) {
+	path := filepath.Join(w.dir, walDeadLetter)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		logx.Errorf("写入审计死信文件失败: %v", err)
+		return
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		buf.Write(r)
+		buf.WriteByte('\n')
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		logx.Errorf("写入审计死信文件失败: %v", err)
+		return
+	}
+	if w.sync {
+		_ = file.Sync()
+	}
+}
+
+func (w *wal) backlog() (int, time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		return 0, time.Time{}
+	}
+	return len(w.pending), w.pending[0].time
+}
+
+func (w *wal) last() json.RawMessage {
+	return w.tail
+}
+
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.active.Close()
+}
+
+func (w *wal) segmentPath(id int64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", id, walExt))
+}
+
+// walSegments 返回目录中的分段编号，升序
+func walSegments(dir string) ([]int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, walExt) {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSuffix(name, walExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// readAt 读取文件中 [offset, offset+n) 的内容
+func readAt(path string, offset, n int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data := make([]byte, n)
+	if _, err := file.ReadAt(data, offset); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -15,6 +15,9 @@ type Config struct {
 
 	// 审计日志配置
 	Audit AuditConfig
+
+	// 敏感数据脱敏配置（日志、审计、链路追踪共用）
+	Mask MaskConfig `json:",optional"`
 }
 
 // LogConfig 日志配置
@@ -24,24 +27,121 @@ type LogConfig struct {
 	Path     string `json:",default=logs"`
 	KeepDays int    `json:",default=7"`
 
+	// 运行时级别调整
+	LevelFile  string `json:",optional"` // 日志级别文件，变更时自动生效
+	AdminToken string `json:",optional"` // 级别管理接口 Bearer Token，为空不开放接口
+
+	// 请求日志
+	BodyLogLimit int `json:",default=0"` // 记录 JSON 请求/响应体的字节上限（脱敏后输出），0 不记录
+
+	// 重复日志折叠（如数据库故障时每个请求输出相同错误）
+	DedupEnabled bool              `json:",default=false"`
+	DedupRules   []DedupRuleConfig `json:",optional"` // 按级别配置，为空时只折叠 error（10 秒窗口）
+
 	// 远程日志上报
 	RemoteEnabled bool   `json:",default=false"`
-	RemoteUrl     string `json:",optional"`    // 远程日志接收地址
-	RemoteBatch   int    `json:",default=100"` // 批量发送数量
-	RemoteTimeout int    `json:",default=5"`   // 超时时间(秒)
+	RemoteSink    string `json:",default=http,options=http|loki|elasticsearch|syslog|kafka"` // 投递目标类型
+	RemoteUrl     string `json:",optional"`                                                  // 接收地址（syslog 为 tcp/udp://host:port，kafka 为 broker 列表）
+	RemoteBatch   int    `json:",default=100"`                                               // 批量发送数量
+	RemoteTimeout int    `json:",default=5"`                                                 // 超时时间(秒)
+
+	// 远程日志压缩与认证
+	RemoteGzip     bool              `json:",default=false"`     // gzip 压缩（kafka 为消息压缩，syslog 不支持）
+	RemoteUsername string            `json:",optional"`          // Basic 认证用户名（kafka 为 SASL/PLAIN）
+	RemotePassword string            `json:",optional"`          // Basic 认证密码
+	RemoteToken    string            `json:",optional"`          // Bearer Token，优先于 Basic 认证
+	RemoteHeaders  map[string]string `json:",optional"`          // 附加请求头（如 Loki 多租户 X-Scope-OrgID）
+	RemoteIndex    string            `json:",default=idrm-logs"` // elasticsearch 索引前缀，按天滚动
+	RemoteTopic    string            `json:",default=idrm-logs"` // kafka topic
+
+	// 远程日志可靠投递
+	RemoteQueueSize  int    `json:",default=10000"`                                             // 内存队列容量(条)
+	RemoteOverflow   string `json:",default=drop_oldest,options=drop_oldest|drop_newest|block"` // 队列满时的策略
+	RemoteMaxRetries int    `json:",default=3"`                                                 // 发送失败重试次数
+	RemoteSpoolDir   string `json:",optional"`                                                  // 重试耗尽后的落盘目录，为空不落盘
+	RemoteSpoolMaxMB int    `json:",default=100"`                                               // 落盘目录容量上限(MB)，超出时删除最早的批次
+	RemoteSpoolFiles int    `json:",default=1000"`                                              // 落盘文件数上限，超出时删除最早的批次
+}
+
+// DedupRuleConfig 重复日志折叠规则
+// 同一级别、caller 和内容的日志在窗口内只输出前 Burst 条，窗口结束时输出带 repeat_count 的汇总
+type DedupRuleConfig struct {
+	Level  string `json:",options=debug|info|error|slow|stat"`
+	Window int    `json:",default=10"` // 折叠窗口(秒)
+	Burst  int    `json:",default=1"`  // 窗口内原样输出条数
 }
 
 // TraceConfig 链路追踪配置
 type TraceConfig struct {
 	Enabled  bool    `json:",default=true"`
-	Endpoint string  `json:",default=http://localhost:4318"` // OTLP endpoint
-	Sampler  float64 `json:",default=1.0"`                   // 采样率 0.0-1.0
-	Batcher  string  `json:",default=otlp"`                  // jaeger/zipkin/otlp
+	Endpoint string  `json:",optional"`                                                              // 为空时使用导出器默认地址（otlpgrpc localhost:4317，otlphttp localhost:4318）
+	Sampler  float64 `json:",default=1.0"`                                                           // 采样率 0.0-1.0
+	Batcher  string  `json:",default=otlp,options=otlp|otlpgrpc|otlphttp|jaeger|zipkin|stdout|file"` // otlp 同 otlpgrpc，jaeger 使用 OTLP gRPC
+
+	// 上下文传播格式：tracecontext(W3C)/baggage/b3(单请求头)/b3multi/jaeger，默认 tracecontext,baggage
+	// 入站请求按所有格式提取，出站请求写出所有格式（如网关使用 B3 时配置 [tracecontext, baggage, b3multi]）
+	Propagators []string `json:",optional"`
+
+	// Baggage：非受信调用方只接受 BaggageAllow 中的成员（默认 client.app、exp.*），租户/用户由本服务认证后写入
+	BaggageAllow   []string `json:",optional"` // 支持 * 后缀前缀匹配
+	BaggageTrusted []string `json:",optional"` // 受信调用方网段（CIDR 或 IP），其 Baggage 全部接受
+	BaggageFields  []string `json:",optional"` // 复制到 Span 属性和日志字段的成员，默认 tenant.id、user.id、client.app
+
+	// 受信反向代理网段（CIDR 或 IP）：只有来自这些地址的连接才读取 X-Forwarded-For 作为客户端 IP（审计、Span 属性），
+	// 为空时一律使用连接地址
+	TrustedProxies []string `json:",optional"`
+
+	// 导出器选项（OTLP/Zipkin）
+	Headers     map[string]string `json:",optional"`                       // 附加请求头（如认证 Token）
+	Compression string            `json:",default=none,options=none|gzip"` // 压缩方式
+
+	// TLS（Endpoint 为 https:// 时使用系统 CA 自动启用）
+	TLSEnabled    bool   `json:",default=false"`
+	TLSSkipVerify bool   `json:",default=false"`
+	CAFile        string `json:",optional"`
+	CertFile      string `json:",optional"` // 客户端证书（mTLS）
+	KeyFile       string `json:",optional"`
+
+	FilePath string `json:",default=logs/trace.jsonl"` // file 导出器输出文件（JSON Lines）
+
+	// Collector 不可用时的处理（启动不等待连接，后台重连）
+	DownPolicy string `json:",default=buffer,options=buffer|drop"` // buffer 缓存后补发，drop 直接丢弃
+	BufferSize int    `json:",default=2048"`                       // buffer 策略缓存的最大 Span 数
+
+	// 采样：默认遵循上游采样决策（parent-based），本地根 Span 按路由规则或 Sampler 比例采样
+	SamplingRules []SamplingRuleConfig `json:",optional"`
+
+	// 尾部采样：比例采样未命中的 trace 中包含错误或耗时超过阈值时仍然保留
+	TailSampling  bool `json:",default=false"`
+	TailLatency   int  `json:",default=1000"`  // 耗时阈值(毫秒)
+	TailMaxTraces int  `json:",default=10000"` // 同时缓存的最大 trace 数
+}
+
+// SamplingRuleConfig 按路由采样规则（前缀匹配，最长前缀优先）
+type SamplingRuleConfig struct {
+	Route string  // 路由前缀
+	Ratio float64 `json:",range=[0:1]"` // 1 总是采样，0 从不采样
 }
 
 // AuditConfig 审计日志配置
 type AuditConfig struct {
 	Enabled bool   `json:",default=false"`
 	Url     string `json:",optional"`    // 审计日志上报地址
-	Buffer  int    `json:",default=100"` // 缓冲区大小
+	Buffer  int    `json:",default=100"` // 批量发送条数
+
+	// 投递保障：事件先写入预写文件再返回，投递失败持续重试直到审计服务确认
+	FlushInterval int    `json:",default=1"`          // 不足一批时的定时发送间隔(秒)
+	WALDir        string `json:",default=logs/audit"` // 预写文件目录，置空时仅缓存在内存
+	WALSync       bool   `json:",default=true"`       // 每次写入后 fsync
+	MaxBackoff    int    `json:",default=60"`         // 重试间隔上限(秒)
+
+	// 防篡改：每条记录带序号和链接上一条的哈希，可用 cmd/auditverify 校验
+	HMACKey string `json:",optional"` // 哈希签名密钥，为空时使用 SHA-256
+	File    string `json:",optional"` // 本地只追加审计文件，如 logs/audit.jsonl
+}
+
+// MaskConfig 脱敏配置
+type MaskConfig struct {
+	// Keys 追加或覆盖键名规则（mobile/idcard/secret），如 bank_card: idcard；规则为空表示取消默认规则
+	Keys map[string]string `json:",optional"`
 }
@@ -0,0 +1,66 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"idrm/pkg/response"
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/trace"
+)
+
+// HealthPath 健康检查接口路径
+const HealthPath = "/health"
+
+// 健康状态
+const (
+	HealthUp       = "up"       // 所有组件正常
+	HealthDegraded = "degraded" // 服务可用，但链路追踪等遥测组件异常
+)
+
+// Health 健康检查结果
+type Health struct {
+	Status string           `json:"status"`
+	Trace  trace.Health     `json:"trace"`
+	Log    *log.RemoteStats `json:"log,omitempty"`   // 未启用远程日志时为空
+	Audit  *audit.Stats     `json:"audit,omitempty"` // 未启用审计日志时为空
+}
+
+// GetHealth 返回默认实例的遥测组件健康状态
+func GetHealth() Health {
+	return std.Health()
+}
+
+// Health 返回遥测组件健康状态
+// 遥测异常不影响业务，只将整体状态标记为 degraded
+func (p *Provider) Health() Health {
+	h := Health{Status: HealthUp, Trace: p.Tracer().Health()}
+	if h.Trace.State == trace.StateDisconnected {
+		h.Status = HealthDegraded
+	}
+	if w := p.Logs().RemoteWriter(); w != nil {
+		stats := w.Stats()
+		h.Log = &stats
+	}
+	if a := p.Audit(); a != nil {
+		stats := a.Stats()
+		h.Audit = &stats
+	}
+	return h
+}
+
+// HealthHandler 默认实例的健康检查接口
+func HealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.Success(w, std.Health())
+	}
+}
+
+// HealthHandler 健康检查接口，始终返回 200，遥测状态见响应体
+//
+//	GET /health  {"status":"degraded","trace":{"state":"disconnected","buffered":120,...}}
+func (p *Provider) HealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.Success(w, p.Health())
+	}
+}
@@ -0,0 +1,228 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/trace"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequestID 请求 ID 请求头
+const HeaderRequestID = "X-Request-ID"
+
+// instrumentationName Tracer 名称
+const instrumentationName = "idrm/pkg/telemetry/httpclient"
+
+// 默认值
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultRetryBackoff = 100 * time.Millisecond
+	defaultMaxBackoff   = 2 * time.Second
+)
+
+// Config 客户端配置
+type Config struct {
+	Name         string        // 下游服务名，写入 Span 的 peer.service 属性
+	Timeout      time.Duration // 整体超时（包含重试），默认 10s，小于 0 表示不限制
+	MaxRetries   int           // 失败重试次数，默认 0 不重试
+	RetryBackoff time.Duration // 首次重试间隔，之后指数增长，默认 100ms
+	MaxBackoff   time.Duration // 最大重试间隔，默认 2s
+}
+
+// New 创建带链路追踪的 http.Client
+//
+//	client := httpclient.New(httpclient.Config{Name: "idrm-catalog", MaxRetries: 2})
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//	resp, err := client.Do(req)
+//	if err == nil {
+//	    err = httpclient.Check(resp)
+//	}
+func New(config Config) *http.Client {
+	timeout := config.Timeout
+	switch {
+	case timeout == 0:
+		timeout = defaultTimeout
+	case timeout < 0:
+		timeout = 0
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewTransport(nil, config),
+	}
+}
+
+// Transport 带链路追踪和重试的 http.RoundTripper
+//   - 为每次调用创建 Client Span，记录方法、地址和状态码，5xx 及网络错误标记为错误
+//   - 注入全局 Propagator 的上下文（W3C traceparent/baggage）和 X-Request-ID
+//   - 网络错误、429 和 5xx 按配置重试：仅幂等方法或可重放请求体（GetBody）的请求
+//   - 网络错误包装为 errorx.ErrCodeExternal
+type Transport struct {
+	next   http.RoundTripper
+	config Config
+}
+
+// NewTransport 包装 next，为 nil 时使用 http.DefaultTransport
+func NewTransport(next http.RoundTripper, config Config) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaultRetryBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultMaxBackoff
+	}
+	return &Transport{next: next, config: config}
+}
+
+// RoundTrip 发送请求
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.method", req.Method),
+		attribute.String("http.url", req.URL.Scheme+"://"+req.URL.Host+req.URL.Path),
+		attribute.String("server.address", req.URL.Hostname()),
+	}
+	if t.config.Name != "" {
+		attrs = append(attrs, attribute.String("peer.service", t.config.Name))
+	}
+	// 使用全局 TracerProvider（trace.Init 时设置），未初始化时为 noop
+	ctx, span := otel.Tracer(instrumentationName).Start(req.Context(), req.Method+" "+req.URL.Host,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient), oteltrace.WithAttributes(attrs...))
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if id := log.RequestID(ctx); id != "" && req.Header.Get(HeaderRequestID) == "" {
+		req.Header.Set(HeaderRequestID, id)
+	}
+
+	retries := 0
+	if t.retryable(req) {
+		retries = t.config.MaxRetries
+	}
+
+	var (
+		resp    *http.Response
+		err     error
+		attempt int
+	)
+	for ; ; attempt++ {
+		if attempt > 0 {
+			if rerr := t.rewind(req); rerr != nil {
+				resp, err = nil, rerr
+				break
+			}
+			trace.AddEvent(span, "retry", attribute.Int("http.resend_count", attempt))
+		}
+
+		resp, err = t.next.RoundTrip(req)
+		if attempt >= retries || ctx.Err() != nil || !shouldRetry(resp, err) {
+			break
+		}
+
+		// 丢弃本次响应后等待重试
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+		if werr := wait(ctx, t.backoff(attempt)); werr != nil {
+			resp, err = nil, werr
+			break
+		}
+	}
+
+	if retries > 0 {
+		span.SetAttributes(attribute.Int("http.retry_count", attempt))
+	}
+	if err != nil {
+		trace.SetError(span, err)
+		return nil, errorx.Wrap(errorx.ErrCodeExternal, err)
+	}
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// retryable 请求是否可以重试：幂等方法且请求体可重放
+func (t *Transport) retryable(req *http.Request) bool {
+	if t.config.MaxRetries <= 0 {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	// 非幂等方法需要调用方设置 Idempotency-Key
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// rewind 重置请求体
+func (t *Transport) rewind(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+// backoff 第 attempt 次重试的等待时间（指数退避，±20% 抖动）
+func (t *Transport) backoff(attempt int) time.Duration {
+	d := t.config.RetryBackoff << attempt
+	if d <= 0 || d > t.config.MaxBackoff {
+		d = t.config.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5*2+1)) - d/5
+	return d + jitter
+}
+
+// shouldRetry 网络错误、429 和 5xx 重试（请求已取消时不重试）
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// wait 等待 d 或 ctx 结束
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Check 将非 2xx 响应转换为 errorx.ErrCodeExternal 错误（响应体由调用方关闭）
+func Check(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return errorx.NewWithMsg(errorx.ErrCodeExternal,
+		fmt.Sprintf("外部服务调用失败: %s %s 返回 %d", resp.Request.Method, resp.Request.URL.Host, resp.StatusCode))
+}
@@ -0,0 +1,174 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/trace/tracetest"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// setupTracer 安装内存记录器及 W3C Propagator
+func setupTracer(t *testing.T) *tracetest.Recorder {
+	t.Helper()
+	rec := tracetest.New(t)
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+	return rec
+}
+
+func TestTransportPropagation(t *testing.T) {
+	rec := setupTracer(t)
+
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ctx := log.WithRequestID(context.Background(), "req-1")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/items?token=x", nil)
+	resp, err := New(Config{Name: "items"}).Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if header.Get("traceparent") == "" {
+		t.Error("traceparent header missing")
+	}
+	if got := header.Get(HeaderRequestID); got != "req-1" {
+		t.Errorf("X-Request-ID = %q, want req-1", got)
+	}
+
+	spans := rec.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["http.status_code"] != "200" || attrs["peer.service"] != "items" {
+		t.Errorf("attributes = %v", attrs)
+	}
+	if strings.Contains(attrs["http.url"], "token") {
+		t.Errorf("http.url must not contain query: %s", attrs["http.url"])
+	}
+	if !strings.Contains(header.Get("traceparent"), spans[0].SpanContext.SpanID().String()) {
+		t.Error("traceparent must carry the client span id")
+	}
+}
+
+func TestTransportRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		failures  int32
+		retries   int
+		wantCalls int32
+		wantCode  int
+	}{
+		{"GET 5xx 重试后成功", http.MethodGet, "", 2, 3, 3, http.StatusOK},
+		{"重试次数用尽返回最后响应", http.MethodGet, "", 5, 1, 2, http.StatusServiceUnavailable},
+		{"PUT 重放请求体", http.MethodPut, `{"a":1}`, 1, 2, 2, http.StatusOK},
+		{"POST 不重试", http.MethodPost, `{"a":1}`, 1, 2, 1, http.StatusServiceUnavailable},
+		{"未配置重试", http.MethodGet, "", 1, 0, 1, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTracer(t)
+
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != tt.body {
+					t.Errorf("body = %q, want %q", body, tt.body)
+				}
+				if atomic.AddInt32(&calls, 1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			client := New(Config{MaxRetries: tt.retries, RetryBackoff: time.Millisecond})
+			req, _ := http.NewRequest(tt.method, srv.URL, strings.NewReader(tt.body))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	rec := setupTracer(t)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	_, err := New(Config{}).Do(req)
+
+	var codeErr *errorx.CodeError
+	if !errors.As(err, &codeErr) || codeErr.Code != errorx.ErrCodeExternal {
+		t.Fatalf("error = %v, want ErrCodeExternal", err)
+	}
+	// 对外消息不含原始错误，Error() 只附带一次原始错误
+	if codeErr.GetMsg() != "外部服务调用失败" || strings.Count(err.Error(), "connection refused") != 1 {
+		t.Errorf("msg = %q, error = %q", codeErr.GetMsg(), err)
+	}
+	rec.AssertCount(t, 1)
+	rec.Span(t, "GET "+req.URL.Host).HasStatus(codes.Error)
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		wantErr bool
+	}{
+		{"2xx 成功", http.StatusNoContent, false},
+		{"4xx 失败", http.StatusBadRequest, true},
+		{"5xx 失败", http.StatusBadGateway, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/x", nil)
+			err := Check(&http.Response{StatusCode: tt.code, Request: req})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if codeErr, ok := errorx.FromError(err); tt.wantErr && (!ok || codeErr.Code != errorx.ErrCodeExternal) {
+				t.Errorf("Check() error = %v, want ErrCodeExternal", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 上下文日志字段名（trace/span 由 logx 从 Span 上下文自动附加）
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldTenantID  = "tenant_id"
+	FieldRoute     = "route" // 由 middleware.Logger 写入，用于按路由覆盖日志级别
+)
+
+// contextKey 上下文值键
+type contextKey string
+
+// scopeKey 请求作用域的上下文键
+type scopeKey struct{}
+
+// scope 请求作用域：作用域内派生的上下文写入的字段，外层上下文同样可读
+type scope struct {
+	mu     sync.Mutex
+	fields map[string]string
+}
+
+// WithScope 开启请求作用域，之后在派生上下文中通过 WithUser、WithTenant 等写入的值，
+// 在 ctx 上也可通过 UserID、TenantID 等读取（如全局中间件在下游路由级认证完成后读取用户）
+func WithScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{fields: map[string]string{}})
+}
+
+// WithRequestID 写入请求 ID，后续 logx.WithContext/FromContext 日志自动携带 request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return withField(ctx, FieldRequestID, requestID)
+}
+
+// WithUser 写入已认证用户 ID
+func WithUser(ctx context.Context, userID string) context.Context {
+	return withField(ctx, FieldUserID, userID)
+}
+
+// WithTenant 写入租户 ID
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return withField(ctx, FieldTenantID, tenantID)
+}
+
+// WithRoute 写入路由，用于按路由覆盖日志级别
+func WithRoute(ctx context.Context, route string) context.Context {
+	return withField(ctx, FieldRoute, route)
+}
+
+// RequestID 返回上下文中的请求 ID
+func RequestID(ctx context.Context) string {
+	return valueOf(ctx, FieldRequestID)
+}
+
+// UserID 返回上下文中的用户 ID
+func UserID(ctx context.Context) string {
+	return valueOf(ctx, FieldUserID)
+}
+
+// TenantID 返回上下文中的租户 ID
+func TenantID(ctx context.Context) string {
+	return valueOf(ctx, FieldTenantID)
+}
+
+// Route 返回上下文中的路由
+func Route(ctx context.Context) string {
+	return valueOf(ctx, FieldRoute)
+}
+
+// withField 同时写入上下文值（供读取）和 logx 上下文字段（供日志输出），空值忽略
+func withField(ctx context.Context, key, value string) context.Context {
+	if value == "" {
+		return ctx
+	}
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.mu.Lock()
+		s.fields[key] = value
+		s.mu.Unlock()
+	}
+	ctx = context.WithValue(ctx, contextKey(key), value)
+	return logx.ContextWithFields(ctx, logx.Field(key, value))
+}
+
+// valueOf 读取上下文值，不存在时读取请求作用域中派生上下文写入的值
+func valueOf(ctx context.Context, key string) string {
+	if v, ok := ctx.Value(contextKey(key)).(string); ok {
+		return v
+	}
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.fields[key]
+	}
+	return ""
+}
+
+// Logger 上下文日志记录器
+// 自动携带请求 ID、trace/span、用户、租户及路由字段，With 创建附加字段的子 Logger
+//
+//	logger := log.FromContext(ctx).With(logx.Field("category_id", id))
+//	logger.Infow("更新类别")
+type Logger struct {
+	logx.Logger
+	ctx    context.Context
+	fields []logx.LogField
+}
+
+// FromContext 返回携带上下文字段的 Logger
+func FromContext(ctx context.Context) *Logger {
+	return &Logger{Logger: logx.WithContext(ctx), ctx: ctx}
+}
+
+// With 返回附加字段的子 Logger，不影响父 Logger
+func (l *Logger) With(fields ...logx.LogField) *Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	merged := make([]logx.LogField, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &Logger{
+		Logger: logx.WithContext(l.ctx).WithFields(merged...),
+		ctx:    l.ctx,
+		fields: merged,
+	}
+}
+
+// WithField 返回附加单个字段的子 Logger
+func (l *Logger) WithField(key string, value any) *Logger {
+	return l.With(logx.Field(key, value))
+}
+
+// Slog 返回同一上下文和字段的 slog.Logger，供使用 log/slog 的代码复用
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(&slogHandler{ctx: l.ctx, fields: l.fields})
+}
@@ -0,0 +1,156 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// captureWriter 记录写入内容的 logx.Writer
+type captureWriter struct {
+	mu      sync.Mutex
+	entries []map[string]any
+}
+
+func (w *captureWriter) record(level string, v any, fields []logx.LogField) {
+	entry := map[string]any{fieldLevel: level, fieldContent: v}
+	for _, f := range fields {
+		entry[f.Key] = f.Value
+	}
+	w.mu.Lock()
+	w.entries = append(w.entries, entry)
+	w.mu.Unlock()
+}
+
+func (w *captureWriter) Alert(any)                            {}
+func (w *captureWriter) Close() error                         { return nil }
+func (w *captureWriter) Severe(any)                           {}
+func (w *captureWriter) Stack(any)                            {}
+func (w *captureWriter) Debug(v any, fields ...logx.LogField) { w.record("debug", v, fields) }
+func (w *captureWriter) Error(v any, fields ...logx.LogField) { w.record("error", v, fields) }
+func (w *captureWriter) Info(v any, fields ...logx.LogField)  { w.record("info", v, fields) }
+func (w *captureWriter) Slow(v any, fields ...logx.LogField)  { w.record("slow", v, fields) }
+func (w *captureWriter) Stat(v any, fields ...logx.LogField)  { w.record("stat", v, fields) }
+
+// captureLogs 替换 logx Writer，测试结束后恢复
+func captureLogs(t *testing.T) *captureWriter {
+	t.Helper()
+	w := &captureWriter{}
+	old := logx.Reset()
+	logx.SetWriter(w)
+	t.Cleanup(func() {
+		logx.Reset()
+		if old != nil {
+			logx.SetWriter(old)
+		}
+	})
+	return w
+}
+
+func TestFromContext(t *testing.T) {
+	w := captureLogs(t)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithUser(ctx, "u-1")
+	ctx = WithTenant(ctx, "t-1")
+	ctx = WithRoute(ctx, "/api/v1/category")
+
+	parent := FromContext(ctx).With(logx.Field("module", "category"))
+	childA := parent.WithField("a", 1)
+	childB := parent.WithField("b", 2)
+
+	parent.Infow("parent")
+	childA.Infow("a")
+	childB.Infow("b")
+
+	if RequestID(ctx) != "req-1" || UserID(ctx) != "u-1" || TenantID(ctx) != "t-1" || Route(ctx) != "/api/v1/category" {
+		t.Errorf("context values = %s/%s/%s/%s", RequestID(ctx), UserID(ctx), TenantID(ctx), Route(ctx))
+	}
+	if len(w.entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(w.entries))
+	}
+
+	tests := []struct {
+		name    string
+		entry   map[string]any
+		want    []string
+		notWant []string
+	}{
+		{"父Logger", w.entries[0], []string{"request_id", "user_id", "tenant_id", "route", "module"}, []string{"a", "b"}},
+		{"子Logger A", w.entries[1], []string{"request_id", "module", "a"}, []string{"b"}},
+		{"子Logger B", w.entries[2], []string{"request_id", "module", "b"}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range tt.want {
+				if _, ok := tt.entry[key]; !ok {
+					t.Errorf("missing field %q in %v", key, tt.entry)
+				}
+			}
+			for _, key := range tt.notWant {
+				if _, ok := tt.entry[key]; ok {
+					t.Errorf("unexpected field %q in %v", key, tt.entry)
+				}
+			}
+		})
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	w := captureLogs(t)
+
+	ctx := WithRequestID(context.Background(), "req-2")
+	logger := slog.New(NewSlogHandler()).With("component", "sdk").WithGroup("db")
+	logger.InfoContext(ctx, "connected", "host", "127.0.0.1", slog.Group("pool", "size", 10))
+	logger.Warn("slow")
+	logger.Error("failed")
+	logger.Debug("dropped") // 默认 info 级别被过滤
+	FromContext(ctx).WithField("module", "x").Slog().Info("bound")
+
+	if len(w.entries) != 4 {
+		t.Fatalf("entries = %d, want 4: %v", len(w.entries), w.entries)
+	}
+
+	first := w.entries[0]
+	if first["request_id"] != "req-2" || first["component"] != "sdk" ||
+		first["db.host"] != "127.0.0.1" || first["db.pool.size"] != int64(10) {
+		t.Errorf("first entry = %v", first)
+	}
+	if caller, _ := first[fieldCaller].(string); !strings.HasPrefix(caller, "log/context_test.go:") {
+		t.Errorf("caller = %q, want log/context_test.go", caller)
+	}
+
+	if w.entries[1][fieldLevel] != "info" || w.entries[2][fieldLevel] != "error" {
+		t.Errorf("levels = %v/%v, want info/error", w.entries[1][fieldLevel], w.entries[2][fieldLevel])
+	}
+	if last := w.entries[3]; last["request_id"] != "req-2" || last["module"] != "x" {
+		t.Errorf("bound entry = %v", last)
+	}
+}
+
+func TestScope(t *testing.T) {
+	outer := WithScope(WithRequestID(context.Background(), "req-1"))
+	if UserID(outer) != "" {
+		t.Fatalf("UserID before auth = %q, want empty", UserID(outer))
+	}
+
+	// 下游（如路由级认证）在派生上下文中写入用户和租户，外层上下文可读
+	inner := WithTenant(WithUser(outer, "u-1"), "t-1")
+	if UserID(outer) != "u-1" || TenantID(outer) != "t-1" || RequestID(outer) != "req-1" {
+		t.Errorf("outer values = %s/%s/%s", RequestID(outer), UserID(outer), TenantID(outer))
+	}
+	if UserID(inner) != "u-1" {
+		t.Errorf("inner UserID = %q", UserID(inner))
+	}
+
+	// 没有作用域时不可见
+	plain := context.Background()
+	WithUser(plain, "u-2")
+	if UserID(plain) != "" {
+		t.Errorf("UserID without scope = %q, want empty", UserID(plain))
+	}
+}
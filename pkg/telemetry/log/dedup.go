@@ -0,0 +1,275 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 重复日志折叠默认值
+const (
+	defaultDedupWindow = 10 * time.Second
+	defaultDedupBurst  = 1
+
+	// dedupMaxKeys 最多跟踪的不同日志数，超出后新日志不折叠直接输出
+	dedupMaxKeys = 10000
+)
+
+// 汇总日志字段
+const (
+	fieldRepeatCount  = "repeat_count"  // 窗口内被折叠的条数
+	fieldRepeatWindow = "repeat_window" // 折叠窗口
+)
+
+// DedupRule 重复日志折叠规则
+// 同一级别、caller 和内容的日志在 Window 内只输出前 Burst 条，
+// 其余计数后在窗口结束时输出一条带 repeat_count 的汇总
+type DedupRule struct {
+	Level  string        // debug/info/error/slow/stat
+	Window time.Duration // 折叠窗口，默认 10s
+	Burst  int           // 窗口内原样输出的条数，默认 1（首条总是输出）
+}
+
+// dedupKey 折叠键
+type dedupKey struct {
+	level   string
+	caller  string
+	content string
+}
+
+// dedupState 窗口内的折叠状态
+type dedupState struct {
+	rule   DedupRule
+	start  time.Time
+	count  int
+	v      any             // 最近一条被折叠日志的内容
+	fields []logx.LogField // 最近一条被折叠日志的字段
+}
+
+// dedupWriter 折叠重复日志的 logx.Writer
+type dedupWriter struct {
+	next  logx.Writer
+	rules map[string]DedupRule
+
+	mu     sync.Mutex
+	states map[dedupKey]*dedupState
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newDedupWriter 创建折叠 Writer，rules 为空时对 error 级别按默认窗口折叠
+func newDedupWriter(next logx.Writer, rules []DedupRule) *dedupWriter {
+	if len(rules) == 0 {
+		rules = []DedupRule{{Level: "error"}}
+	}
+
+	w := &dedupWriter{
+		next:   next,
+		rules:  make(map[string]DedupRule, len(rules)),
+		states: make(map[dedupKey]*dedupState),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	tick := time.Duration(0)
+	for _, rule := range rules {
+		if rule.Window <= 0 {
+			rule.Window = defaultDedupWindow
+		}
+		if rule.Burst <= 0 {
+			rule.Burst = defaultDedupBurst
+		}
+		w.rules[rule.Level] = rule
+		if tick == 0 || rule.Window < tick {
+			tick = rule.Window
+		}
+	}
+
+	go w.loop(tick)
+	return w
+}
+
+// installDedupWriter 用 dedupWriter 包装 logx 当前的 Writer
+func installDedupWriter(rules []DedupRule) *dedupWriter {
+	next := logx.Reset()
+	if next == nil {
+		return nil
+	}
+	w := newDedupWriter(next, rules)
+	logx.SetWriter(w)
+	return w
+}
+
+// loop 定期输出到期窗口的汇总，保证持续出现的日志按窗口周期输出
+func (w *dedupWriter) loop(tick time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			w.flush(now, false)
+		case <-w.stop:
+			w.flush(time.Now(), true)
+			return
+		}
+	}
+}
+
+// flush 输出到期（或全部）窗口的汇总并清理状态
+func (w *dedupWriter) flush(now time.Time, all bool) {
+	var (
+		summaries []*dedupState
+		summaryOf []string // 汇总对应的级别
+	)
+
+	w.mu.Lock()
+	for key, st := range w.states {
+		if !all && now.Sub(st.start) < st.rule.Window {
+			continue
+		}
+		delete(w.states, key)
+		if st.count > st.rule.Burst {
+			summaries = append(summaries, st)
+			summaryOf = append(summaryOf, key.level)
+		}
+	}
+	w.mu.Unlock()
+
+	for i, st := range summaries {
+		w.summary(summaryOf[i], st)
+	}
+}
+
+// summary 输出折叠汇总（内容和字段取最近一条被折叠的日志）
+func (w *dedupWriter) summary(level string, st *dedupState) {
+	fields := make([]logx.LogField, 0, len(st.fields)+2)
+	fields = append(fields, st.fields...)
+	fields = append(fields,
+		logx.Field(fieldRepeatCount, st.count-st.rule.Burst),
+		logx.Field(fieldRepeatWindow, st.rule.Window.String()),
+	)
+	w.emit(level, st.v, fields)
+}
+
+// write 按规则折叠，未配置规则的级别直接输出
+func (w *dedupWriter) write(level string, v any, fields []logx.LogField) {
+	rule, ok := w.rules[level]
+	if !ok {
+		w.emit(level, v, fields)
+		return
+	}
+
+	key := dedupKey{level: level, caller: callerOf(fields), content: fmt.Sprint(v)}
+	now := time.Now()
+
+	w.mu.Lock()
+	var expired *dedupState
+	st := w.states[key]
+	if st != nil && now.Sub(st.start) >= rule.Window {
+		delete(w.states, key)
+		if st.count > st.rule.Burst {
+			expired = st
+		}
+		st = nil
+	}
+	if st == nil && len(w.states) < dedupMaxKeys {
+		st = &dedupState{rule: rule, start: now}
+		w.states[key] = st
+	}
+
+	pass := true
+	if st != nil {
+		st.count++
+		if st.count > rule.Burst {
+			pass = false
+			st.v, st.fields = v, fields
+		}
+	}
+	w.mu.Unlock()
+
+	if expired != nil {
+		w.summary(level, expired)
+	}
+	if pass {
+		w.emit(level, v, fields)
+	}
+}
+
+// emit 按级别写入下游 Writer
+func (w *dedupWriter) emit(level string, v any, fields []logx.LogField) {
+	switch level {
+	case "debug":
+		w.next.Debug(v, fields...)
+	case "info":
+		w.next.Info(v, fields...)
+	case "error":
+		w.next.Error(v, fields...)
+	case "slow":
+		w.next.Slow(v, fields...)
+	case "stat":
+		w.next.Stat(v, fields...)
+	}
+}
+
+// callerOf 取 caller 字段
+func callerOf(fields []logx.LogField) string {
+	for _, f := range fields {
+		if f.Key == fieldCaller {
+			caller, _ := f.Value.(string)
+			return caller
+		}
+	}
+	return ""
+}
+
+// shutdown 停止汇总协程并输出所有未完成窗口的汇总，可重复调用
+func (w *dedupWriter) shutdown() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+}
+
+func (w *dedupWriter) Alert(v any) {
+	w.next.Alert(v)
+}
+
+func (w *dedupWriter) Close() error {
+	w.shutdown()
+	return w.next.Close()
+}
+
+func (w *dedupWriter) Debug(v any, fields ...logx.LogField) {
+	w.write("debug", v, fields)
+}
+
+func (w *dedupWriter) Error(v any, fields ...logx.LogField) {
+	w.write("error", v, fields)
+}
+
+func (w *dedupWriter) Info(v any, fields ...logx.LogField) {
+	w.write("info", v, fields)
+}
+
+func (w *dedupWriter) Severe(v any) {
+	w.next.Severe(v)
+}
+
+func (w *dedupWriter) Slow(v any, fields ...logx.LogField) {
+	w.write("slow", v, fields)
+}
+
+func (w *dedupWriter) Stack(v any) {
+	w.next.Stack(v)
+}
+
+func (w *dedupWriter) Stat(v any, fields ...logx.LogField) {
+	w.write("stat", v, fields)
+}
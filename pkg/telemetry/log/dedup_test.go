@@ -0,0 +1,87 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+func TestDedupWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      DedupRule
+		level     string
+		writes    int
+		wantPass  int // 窗口内直接输出的条数
+		wantCount any // 汇总中的 repeat_count，nil 表示无汇总
+	}{
+		{"首条输出其余折叠", DedupRule{Level: "error", Window: time.Hour}, "error", 5, 1, 4},
+		{"Burst内原样输出", DedupRule{Level: "error", Window: time.Hour, Burst: 3}, "error", 5, 3, 2},
+		{"未超出Burst无汇总", DedupRule{Level: "error", Window: time.Hour, Burst: 3}, "error", 2, 2, nil},
+		{"未配置级别不折叠", DedupRule{Level: "error", Window: time.Hour}, "info", 5, 5, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &captureWriter{}
+			w := newDedupWriter(next, []DedupRule{tt.rule})
+			for i := 0; i < tt.writes; i++ {
+				fields := []logx.LogField{logx.Field(fieldCaller, "db/conn.go:10"), logx.Field("request_id", i)}
+				if tt.level == "error" {
+					w.Error("connection refused", fields...)
+				} else {
+					w.Info("connection refused", fields...)
+				}
+			}
+			if got := len(next.entries); got != tt.wantPass {
+				t.Fatalf("passed = %d, want %d", got, tt.wantPass)
+			}
+
+			w.shutdown()
+			if tt.wantCount == nil {
+				if len(next.entries) != tt.wantPass {
+					t.Errorf("unexpected summary: %v", next.entries[len(next.entries)-1])
+				}
+				return
+			}
+			summary := next.entries[len(next.entries)-1]
+			if summary[fieldRepeatCount] != tt.wantCount || summary["request_id"] != tt.writes-1 {
+				t.Errorf("summary = %v, want repeat_count %v from last occurrence", summary, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestDedupWriterPeriodicSummary(t *testing.T) {
+	next := &captureWriter{}
+	w := newDedupWriter(next, []DedupRule{{Level: "error", Window: 50 * time.Millisecond}})
+	defer w.shutdown()
+
+	// 不同 caller 视为不同日志，各自输出首条
+	w.Error("query failed", logx.Field(fieldCaller, "a.go:1"))
+	w.Error("query failed", logx.Field(fieldCaller, "b.go:1"))
+	w.Error("query failed", logx.Field(fieldCaller, "a.go:1"))
+	w.Error("query failed", logx.Field(fieldCaller, "a.go:1"))
+
+	// 没有新日志时汇总也会在窗口结束后输出
+	deadline := time.Now().Add(time.Second)
+	for {
+		next.mu.Lock()
+		n := len(next.entries)
+		next.mu.Unlock()
+		if n == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("entries = %d, want 2 first occurrences and 1 summary", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	next.mu.Lock()
+	defer next.mu.Unlock()
+	if got := next.entries[2][fieldRepeatCount]; got != 2 {
+		t.Errorf("repeat_count = %v, want 2", got)
+	}
+}
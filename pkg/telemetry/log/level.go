@@ -0,0 +1,451 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 日志级别作用范围
+const (
+	ScopeGlobal  = "global"  // 全局级别
+	ScopePackage = "package" // 按包覆盖，匹配 caller 的目录（如 db/trace.go:76 的 db）
+	ScopeRoute   = "route"   // 按路由覆盖，前缀匹配上下文中的 route 字段
+)
+
+// 级别变更来源
+const (
+	SourceAPI  = "api"  // 管理接口
+	SourceFile = "file" // 级别文件
+	SourceTTL  = "ttl"  // 到期自动恢复
+)
+
+// levelValues 级别名称与 logx 级别的映射
+var levelValues = map[string]uint32{
+	"debug":  logx.DebugLevel,
+	"info":   logx.InfoLevel,
+	"error":  logx.ErrorLevel,
+	"severe": logx.SevereLevel,
+}
+
+// LevelChange 日志级别变更记录
+type LevelChange struct {
+	Scope    string        `json:"scope"`
+	Name     string        `json:"name,omitempty"`   // 包名或路由前缀，全局为空
+	Before   string        `json:"before,omitempty"` // 变更前级别，空表示之前没有覆盖
+	After    string        `json:"after,omitempty"`  // 变更后级别，空表示移除覆盖
+	TTL      time.Duration `json:"ttl,omitempty"`    // 到期后恢复为 Before，0 表示不恢复
+	Source   string        `json:"source"`
+	Operator string        `json:"operator,omitempty"`
+}
+
+// LevelOverride 级别覆盖规则
+type LevelOverride struct {
+	Scope     string     `json:"scope"`
+	Name      string     `json:"name"`
+	Level     string     `json:"level"`
+	Source    string     `json:"source"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// LevelState 当前日志级别
+type LevelState struct {
+	Global          string          `json:"global"`
+	GlobalExpiresAt *time.Time      `json:"global_expires_at,omitempty"`
+	Overrides       []LevelOverride `json:"overrides"`
+}
+
+// levelKey 级别规则键
+type levelKey struct {
+	scope string
+	name  string
+}
+
+// levelRule 级别规则
+type levelRule struct {
+	level     string
+	source    string
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// levelSnapshot 供 levelWriter 无锁读取的覆盖规则快照
+type levelSnapshot struct {
+	global   uint32
+	lowest   uint32 // 所有规则中最详细的级别，供 slog Handler.Enabled 快速判断
+	packages map[string]uint32
+	routes   []routeLevel // 按前缀长度降序，最长前缀优先
+}
+
+// routeLevel 路由级别
+type routeLevel struct {
+	prefix string
+	level  uint32
+}
+
+// levelController 日志级别控制器
+// logx 的全局级别设为所有规则中最详细的级别，由 levelWriter 按包/路由再次过滤
+type levelController struct {
+	mu       sync.Mutex
+	rules    map[levelKey]*levelRule
+	hooks    []func(ctx context.Context, change LevelChange)
+	snapshot atomic.Pointer[levelSnapshot]
+
+	// pending 注册回调前发生的变更（如启动时加载级别文件），注册第一个回调时补发
+	pending []LevelChange
+}
+
+// maxPendingChanges 等待补发的变更上限
+const maxPendingChanges = 256
+
+var levels = newLevelController("info")
+
+// newLevelController 创建级别控制器
+func newLevelController(global string) *levelController {
+	c := &levelController{
+		rules: map[levelKey]*levelRule{
+			{scope: ScopeGlobal}: {level: global},
+		},
+	}
+	c.rebuild()
+	return c
+}
+
+// reset 按配置重置全局级别并清空覆盖规则（日志初始化时调用，不触发回调）
+func (c *levelController) reset(global string) {
+	global = strings.ToLower(global)
+	if _, ok := levelValues[global]; !ok {
+		global = "info"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rule := range c.rules {
+		if rule.timer != nil {
+			rule.timer.Stop()
+		}
+	}
+	c.rules = map[levelKey]*levelRule{
+		{scope: ScopeGlobal}: {level: global},
+	}
+	c.pending = nil
+	c.apply()
+}
+
+// OnLevelChange 注册级别变更回调（如写入审计日志）
+// 注册前已发生的变更（日志初始化时加载级别文件，此时审计日志尚未创建）在注册第一个回调时补发
+func OnLevelChange(fn func(ctx context.Context, change LevelChange)) {
+	levels.onChange(fn)
+}
+
+// onChange 注册回调并补发等待中的变更
+func (c *levelController) onChange(fn func(ctx context.Context, change LevelChange)) {
+	c.mu.Lock()
+	c.hooks = append(c.hooks, fn)
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, change := range pending {
+		fn(context.Background(), change)
+	}
+}
+
+// SetLevel 修改日志级别
+// scope 为 global 时 name 为空；level 为空表示移除覆盖（global 不允许）；
+// ttl 大于 0 时到期自动恢复为修改前的级别
+func SetLevel(ctx context.Context, change LevelChange) (LevelChange, error) {
+	return levels.set(ctx, change, nil)
+}
+
+// Levels 返回当前日志级别及覆盖规则
+func Levels() LevelState {
+	return levels.state()
+}
+
+// set 修改规则并通知回调
+// expect 不为空时仅当当前规则仍为 expect 才修改（到期恢复时避免覆盖更新的变更）
+func (c *levelController) set(ctx context.Context, change LevelChange, expect *levelRule) (LevelChange, error) {
+	if err := validateLevelChange(&change); err != nil {
+		return change, err
+	}
+
+	c.mu.Lock()
+	key := levelKey{scope: change.Scope, name: change.Name}
+	old := c.rules[key]
+	if expect != nil && old != expect {
+		c.mu.Unlock()
+		return change, nil
+	}
+	if old != nil {
+		change.Before = old.level
+		if old.timer != nil {
+			old.timer.Stop()
+		}
+	}
+
+	if change.After == "" {
+		delete(c.rules, key)
+	} else {
+		rule := &levelRule{level: change.After, source: change.Source}
+		if change.TTL > 0 {
+			rule.expiresAt = time.Now().Add(change.TTL)
+			rule.timer = time.AfterFunc(change.TTL, c.revertFunc(key, rule, change.Before))
+		}
+		c.rules[key] = rule
+	}
+	c.apply()
+	hooks := c.hooks
+	if len(hooks) == 0 && len(c.pending) < maxPendingChanges {
+		c.pending = append(c.pending, change)
+	}
+	c.mu.Unlock()
+
+	logx.WithContext(ctx).Infow("日志级别已变更",
+		logx.Field("scope", change.Scope),
+		logx.Field("name", change.Name),
+		logx.Field("before", change.Before),
+		logx.Field("after", change.After),
+		logx.Field("ttl", change.TTL),
+		logx.Field("source", change.Source),
+	)
+	for _, hook := range hooks {
+		hook(ctx, change)
+	}
+	return change, nil
+}
+
+// revertFunc 到期恢复：规则未被再次修改时恢复为 before
+func (c *levelController) revertFunc(key levelKey, rule *levelRule, before string) func() {
+	return func() {
+		_, _ = c.set(context.Background(), LevelChange{
+			Scope:  key.scope,
+			Name:   key.name,
+			After:  before,
+			Source: SourceTTL,
+		}, rule)
+	}
+}
+
+// apply 重建快照并将 logx 全局级别设为最详细的级别，调用方持有锁
+func (c *levelController) apply() {
+	logx.SetLevel(c.rebuild())
+}
+
+// rebuild 重建快照，返回所有规则中最详细的级别
+func (c *levelController) rebuild() uint32 {
+	snap := &levelSnapshot{packages: make(map[string]uint32)}
+	lowest := logx.SevereLevel
+	for key, rule := range c.rules {
+		level := levelValues[rule.level]
+		if level < lowest {
+			lowest = level
+		}
+		switch key.scope {
+		case ScopeGlobal:
+			snap.global = level
+		case ScopePackage:
+			snap.packages[key.name] = level
+		case ScopeRoute:
+			snap.routes = append(snap.routes, routeLevel{prefix: key.name, level: level})
+		}
+	}
+	sort.Slice(snap.routes, func(i, j int) bool {
+		return len(snap.routes[i].prefix) > len(snap.routes[j].prefix)
+	})
+
+	snap.lowest = lowest
+	c.snapshot.Store(snap)
+	return lowest
+}
+
+// state 当前级别
+func (c *levelController) state() LevelState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := LevelState{Overrides: make([]LevelOverride, 0, len(c.rules))}
+	for key, rule := range c.rules {
+		var expiresAt *time.Time
+		if !rule.expiresAt.IsZero() {
+			t := rule.expiresAt
+			expiresAt = &t
+		}
+		if key.scope == ScopeGlobal {
+			state.Global = rule.level
+			state.GlobalExpiresAt = expiresAt
+			continue
+		}
+		state.Overrides = append(state.Overrides, LevelOverride{
+			Scope:     key.scope,
+			Name:      key.name,
+			Level:     rule.level,
+			Source:    rule.source,
+			ExpiresAt: expiresAt,
+		})
+	}
+	sort.Slice(state.Overrides, func(i, j int) bool {
+		a, b := state.Overrides[i], state.Overrides[j]
+		return a.Scope < b.Scope || a.Scope == b.Scope && a.Name < b.Name
+	})
+	return state
+}
+
+// overrides 指定来源的覆盖规则
+func (c *levelController) overrides(source string) []levelKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var keys []levelKey
+	for key, rule := range c.rules {
+		if key.scope != ScopeGlobal && rule.source == source {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// validateLevelChange 校验并规范化级别变更
+func validateLevelChange(change *LevelChange) error {
+	change.After = strings.ToLower(strings.TrimSpace(change.After))
+	if change.After != "" {
+		if _, ok := levelValues[change.After]; !ok {
+			return fmt.Errorf("unknown log level: %s", change.After)
+		}
+	}
+
+	switch change.Scope {
+	case ScopeGlobal:
+		change.Name = ""
+		if change.After == "" {
+			return fmt.Errorf("global level is required")
+		}
+	case ScopePackage, ScopeRoute:
+		if change.Name == "" {
+			return fmt.Errorf("name is required for %s scope", change.Scope)
+		}
+	default:
+		return fmt.Errorf("unknown level scope: %s", change.Scope)
+	}
+
+	if change.TTL < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+	return nil
+}
+
+// allow 按包/路由覆盖规则判断是否输出
+// 没有覆盖规则时 logx 已按全局级别过滤，直接放行
+func (s *levelSnapshot) allow(level uint32, fields []logx.LogField) bool {
+	if len(s.packages) == 0 && len(s.routes) == 0 {
+		return true
+	}
+
+	threshold, matched := s.global, false
+	for _, f := range fields {
+		var (
+			l  uint32
+			ok bool
+		)
+		switch f.Key {
+		case fieldCaller:
+			l, ok = s.packageLevel(fmt.Sprint(f.Value))
+		case FieldRoute:
+			l, ok = s.routeLevel(fmt.Sprint(f.Value))
+		}
+		// 同时命中多条规则时取最详细的级别
+		if ok && (!matched || l < threshold) {
+			threshold, matched = l, true
+		}
+	}
+	return level >= threshold
+}
+
+// packageLevel caller 形如 db/trace.go:76，取目录部分匹配包名
+func (s *levelSnapshot) packageLevel(caller string) (uint32, bool) {
+	pkg, _, ok := strings.Cut(caller, "/")
+	if !ok {
+		return 0, false
+	}
+	level, ok := s.packages[pkg]
+	return level, ok
+}
+
+// routeLevel 最长前缀匹配路由
+func (s *levelSnapshot) routeLevel(route string) (uint32, bool) {
+	for _, r := range s.routes {
+		if strings.HasPrefix(route, r.prefix) {
+			return r.level, true
+		}
+	}
+	return 0, false
+}
+
+// levelWriter 按包/路由覆盖规则过滤日志的 logx.Writer
+type levelWriter struct {
+	next logx.Writer
+}
+
+// installLevelWriter 用 levelWriter 包装 logx 当前的 Writer
+func installLevelWriter() {
+	if w := logx.Reset(); w != nil {
+		logx.SetWriter(&levelWriter{next: w})
+	}
+}
+
+func (w *levelWriter) allow(level uint32, fields []logx.LogField) bool {
+	return levels.snapshot.Load().allow(level, fields)
+}
+
+func (w *levelWriter) Alert(v any) {
+	w.next.Alert(v)
+}
+
+func (w *levelWriter) Close() error {
+	return w.next.Close()
+}
+
+func (w *levelWriter) Debug(v any, fields ...logx.LogField) {
+	if w.allow(logx.DebugLevel, fields) {
+		w.next.Debug(v, fields...)
+	}
+}
+
+func (w *levelWriter) Error(v any, fields ...logx.LogField) {
+	if w.allow(logx.ErrorLevel, fields) {
+		w.next.Error(v, fields...)
+	}
+}
+
+func (w *levelWriter) Info(v any, fields ...logx.LogField) {
+	if w.allow(logx.InfoLevel, fields) {
+		w.next.Info(v, fields...)
+	}
+}
+
+func (w *levelWriter) Severe(v any) {
+	w.next.Severe(v)
+}
+
+func (w *levelWriter) Slow(v any, fields ...logx.LogField) {
+	if w.allow(logx.ErrorLevel, fields) {
+		w.next.Slow(v, fields...)
+	}
+}
+
+func (w *levelWriter) Stack(v any) {
+	w.next.Stack(v)
+}
+
+func (w *levelWriter) Stat(v any, fields ...logx.LogField) {
+	if w.allow(logx.InfoLevel, fields) {
+		w.next.Stat(v, fields...)
+	}
+}
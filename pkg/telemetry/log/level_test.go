@@ -0,0 +1,197 @@
+package log
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+func TestLevelSnapshotAllow(t *testing.T) {
+	c := newLevelController("info")
+	for _, change := range []LevelChange{
+		{Scope: ScopePackage, Name: "db", After: "debug"},
+		{Scope: ScopePackage, Name: "noisy", After: "error"},
+		{Scope: ScopeRoute, Name: "/api/v1/category", After: "debug"},
+		{Scope: ScopeRoute, Name: "/api/v1/category/export", After: "error"},
+	} {
+		if _, err := c.set(context.Background(), change, nil); err != nil {
+			t.Fatalf("set(%+v) error = %v", change, err)
+		}
+	}
+	snap := c.snapshot.Load()
+
+	tests := []struct {
+		name   string
+		level  uint32
+		fields []logx.LogField
+		want   bool
+	}{
+		{"全局info放行info", logx.InfoLevel, []logx.LogField{logx.Field("caller", "logic/a.go:1")}, true},
+		{"全局info过滤debug", logx.DebugLevel, []logx.LogField{logx.Field("caller", "logic/a.go:1")}, false},
+		{"包覆盖debug", logx.DebugLevel, []logx.LogField{logx.Field("caller", "db/trace.go:76")}, true},
+		{"包覆盖error过滤info", logx.InfoLevel, []logx.LogField{logx.Field("caller", "noisy/x.go:3")}, false},
+		{"路由前缀匹配", logx.DebugLevel, []logx.LogField{logx.Field("route", "/api/v1/category/1")}, true},
+		{"最长路由前缀优先", logx.InfoLevel, []logx.LogField{logx.Field("route", "/api/v1/category/export")}, false},
+		{"同时命中取最详细", logx.DebugLevel, []logx.LogField{
+			logx.Field("caller", "noisy/x.go:3"),
+			logx.Field("route", "/api/v1/category/1"),
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snap.allow(tt.level, tt.fields); got != tt.want {
+				t.Errorf("allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLevelValidation(t *testing.T) {
+	c := newLevelController("info")
+	tests := []struct {
+		name   string
+		change LevelChange
+	}{
+		{"未知级别", LevelChange{Scope: ScopeGlobal, After: "verbose"}},
+		{"全局级别不能为空", LevelChange{Scope: ScopeGlobal}},
+		{"包名不能为空", LevelChange{Scope: ScopePackage, After: "debug"}},
+		{"未知范围", LevelChange{Scope: "module", Name: "db", After: "debug"}},
+		{"TTL不能为负", LevelChange{Scope: ScopeGlobal, After: "debug", TTL: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.set(context.Background(), tt.change, nil); err == nil {
+				t.Errorf("set(%+v) error = nil, want error", tt.change)
+			}
+		})
+	}
+}
+
+func TestSetLevelTTL(t *testing.T) {
+	c := newLevelController("info")
+
+	var (
+		mu      sync.Mutex
+		changes []LevelChange
+	)
+	c.hooks = append(c.hooks, func(ctx context.Context, change LevelChange) {
+		mu.Lock()
+		changes = append(changes, change)
+		mu.Unlock()
+	})
+
+	if _, err := c.set(context.Background(), LevelChange{Scope: ScopeGlobal, After: "debug", TTL: 20 * time.Millisecond, Source: SourceAPI}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.set(context.Background(), LevelChange{Scope: ScopePackage, Name: "db", After: "debug", TTL: 20 * time.Millisecond}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.state(); got.Global != "debug" || len(got.Overrides) != 1 || got.GlobalExpiresAt == nil {
+		t.Fatalf("state = %+v", got)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if got := c.state(); got.Global != "info" || len(got.Overrides) != 0 {
+		t.Errorf("state after ttl = %+v, want global info without overrides", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 4 || changes[2].Source != SourceTTL || changes[3].Source != SourceTTL {
+		t.Errorf("changes = %+v, want 2 changes and 2 ttl reverts", changes)
+	}
+}
+
+func TestWatchLevelFile(t *testing.T) {
+	defer levels.reset("info")
+	levels.reset("info")
+
+	path := filepath.Join(t.TempDir(), "log-level.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Level: error\nOverrides:\n  - Package: db\n    Level: debug\n  - Route: /api/v1\n    Level: debug\n")
+
+	w, err := WatchLevelFile(path)
+	if err != nil {
+		t.Fatalf("WatchLevelFile() error = %v", err)
+	}
+	defer w.Close()
+
+	if got := Levels(); got.Global != "error" || len(got.Overrides) != 2 {
+		t.Fatalf("initial state = %+v", got)
+	}
+
+	// 管理接口设置的规则不受文件影响
+	if _, err := SetLevel(context.Background(), LevelChange{Scope: ScopePackage, Name: "svc", After: "debug", Source: SourceAPI}); err != nil {
+		t.Fatal(err)
+	}
+	write("Level: info\nOverrides:\n  - Package: db\n    Level: debug\n")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := Levels()
+		if got.Global == "info" && len(got.Overrides) == 2 &&
+			got.Overrides[0].Name == "db" && got.Overrides[1].Name == "svc" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("state after reload = %+v", got)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestLevelChangeReplay(t *testing.T) {
+	c := newLevelController("info")
+
+	// 注册回调前的变更（如启动时加载级别文件）在注册时补发
+	if _, err := c.set(context.Background(), LevelChange{Scope: ScopePackage, Name: "db", After: "debug", Source: SourceFile}, nil); err != nil {
+		t.Fatal(err)
+	}
+	var changes []LevelChange
+	c.onChange(func(ctx context.Context, change LevelChange) { changes = append(changes, change) })
+	if len(changes) != 1 || changes[0].Source != SourceFile || changes[0].Name != "db" {
+		t.Fatalf("replayed changes = %+v, want the file change", changes)
+	}
+
+	// 之后的变更直接回调，不再补发
+	if _, err := c.set(context.Background(), LevelChange{Scope: ScopeGlobal, After: "error", Source: SourceAPI}, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.onChange(func(ctx context.Context, change LevelChange) {
+		t.Errorf("second hook replayed %+v", change)
+	})
+	if len(changes) != 2 {
+		t.Errorf("changes = %+v, want 2", changes)
+	}
+}
+
+func TestLevelHandlerOperator(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"Token 指纹", "secret", "admin-token:2bb80d53"},
+		{"轮换后指纹不同", "rotated", "admin-token:f42546d5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := operatorOf(tt.token); got != tt.want {
+				t.Errorf("operatorOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package log
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// levelFileDebounce 文件变更合并间隔（编辑器保存时通常产生多个事件）
+const levelFileDebounce = 200 * time.Millisecond
+
+// levelFile 日志级别文件格式（YAML），示例：
+//
+//	Level: info
+//	Overrides:
+//	  - Package: db
+//	    Level: debug
+//	  - Route: /api/v1/category
+//	    Level: debug
+type levelFile struct {
+	Level     string `json:",optional"`
+	Overrides []struct {
+		Package string `json:",optional"`
+		Route   string `json:",optional"`
+		Level   string
+	} `json:",optional"`
+}
+
+// LevelWatcher 日志级别文件监听器
+type LevelWatcher struct {
+	path    string
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// WatchLevelFile 监听日志级别文件，启动时及文件变更时应用其中的级别
+// 文件中的覆盖规则为全量配置：文件中删除的规则会被移除，管理接口设置的规则不受影响
+func WatchLevelFile(path string) (*LevelWatcher, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// 监听所在目录：编辑器和 K8s ConfigMap 通过重命名替换文件，直接监听文件会丢失事件
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	w := &LevelWatcher{
+		path:    path,
+		watcher: watcher,
+		done:    make(chan struct{}),
+	}
+	w.reload()
+	go w.loop()
+
+	return w, nil
+}
+
+// loop 处理文件事件，合并短时间内的多次变更
+func (w *LevelWatcher) loop() {
+	defer close(w.done)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.affects(event.Name) {
+				debounce = time.After(levelFileDebounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logx.Errorw("日志级别文件监听出错", logx.Field("error", err))
+		case <-debounce:
+			debounce = nil
+			w.reload()
+		}
+	}
+}
+
+// affects 事件是否涉及级别文件（K8s ConfigMap 更新的是 ..data 符号链接）
+func (w *LevelWatcher) affects(name string) bool {
+	name = filepath.Clean(name)
+	return name == w.path || filepath.Base(name) == "..data"
+}
+
+// reload 读取文件并应用级别，文件不存在时保持当前级别
+func (w *LevelWatcher) reload() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logx.Errorw("读取日志级别文件失败", logx.Field("file", w.path), logx.Field("error", err))
+		}
+		return
+	}
+
+	var file levelFile
+	if err := conf.LoadFromYamlBytes(data, &file); err != nil {
+		logx.Errorw("解析日志级别文件失败", logx.Field("file", w.path), logx.Field("error", err))
+		return
+	}
+
+	ctx := context.Background()
+	apply := func(change LevelChange) {
+		change.Source = SourceFile
+		change.Operator = w.path
+		if _, err := levels.set(ctx, change, nil); err != nil {
+			logx.Errorw("应用日志级别失败", logx.Field("file", w.path), logx.Field("error", err))
+		}
+	}
+
+	state := levels.state()
+	if file.Level != "" && !strings.EqualFold(file.Level, state.Global) {
+		apply(LevelChange{Scope: ScopeGlobal, After: file.Level})
+	}
+
+	current := make(map[levelKey]string)
+	for _, o := range state.Overrides {
+		current[levelKey{scope: o.Scope, name: o.Name}] = o.Level
+	}
+
+	wanted := make(map[levelKey]bool)
+	for _, o := range file.Overrides {
+		key := levelKey{scope: ScopePackage, name: o.Package}
+		if o.Route != "" {
+			key = levelKey{scope: ScopeRoute, name: o.Route}
+		}
+		wanted[key] = true
+		if !strings.EqualFold(current[key], o.Level) {
+			apply(LevelChange{Scope: key.scope, Name: key.name, After: o.Level})
+		}
+	}
+
+	// 移除文件中已删除的规则
+	for _, key := range levels.overrides(SourceFile) {
+		if !wanted[key] {
+			apply(LevelChange{Scope: key.scope, Name: key.name})
+		}
+	}
+}
+
+// Close 停止监听
+func (w *LevelWatcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
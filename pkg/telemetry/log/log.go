@@ -1,31 +1,68 @@
 package log
 
 import (
-	"io"
+	"context"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
-var (
-	remoteWriter *RemoteWriter
-)
+// std 默认实例，由 Init 设置；包级函数（Close、GetRemoteWriter）使用
+var std *System
+
+// System 日志系统实例，持有远程 Writer、级别文件监听和重复日志折叠，由 Init 创建并由 telemetry.Provider 持有
+// go-zero logx 的输出链（本地文件、脱敏/折叠/级别过滤包装、远程 Writer）为进程级：
+// 同一进程只有一个生效的日志配置，再次 Init 会替换输出链，应先关闭上一个实例
+// 方法对 nil 安全
+type System struct {
+	remote  *RemoteWriter
+	watcher *LevelWatcher
+	dedup   *dedupWriter
+}
 
 // LogConfig 日志配置
 type LogConfig struct {
+	// 服务信息（附加到远程日志的资源字段）
+	ServiceVersion string
+	Environment    string
+
 	Level    string
 	Mode     string
 	Path     string
 	KeepDays int
 
+	LevelFile string // 日志级别文件，变更时自动生效
+
+	DedupEnabled bool        // 折叠重复日志
+	DedupRules   []DedupRule // 按级别配置折叠规则，为空时只折叠 error
+
 	RemoteEnabled bool
+	RemoteSink    string
 	RemoteUrl     string
 	RemoteBatch   int
 	RemoteTimeout int
+
+	RemoteGzip     bool
+	RemoteUsername string
+	RemotePassword string
+	RemoteToken    string
+	RemoteHeaders  map[string]string
+	RemoteIndex    string
+	RemoteTopic    string
+
+	RemoteQueueSize  int
+	RemoteOverflow   string
+	RemoteMaxRetries int
+	RemoteSpoolDir   string
+	RemoteSpoolMaxMB int
+	RemoteSpoolFiles int
 }
 
-// Init 初始化日志系统
-func Init(config LogConfig, serviceName string) {
+// Init 初始化日志系统：配置 logx 输出链，创建实例并设为默认实例
+func Init(config LogConfig, serviceName string) *System {
+	sys := &System{}
+	std = sys
+
 	// 1. 配置 go-zero logx
 	logConf := logx.LogConf{
 		ServiceName: serviceName,
@@ -39,47 +76,126 @@ func Init(config LogConfig, serviceName string) {
 	if err := logx.SetUp(logConf); err != nil {
 		panic(err)
 	}
+	levels.reset(config.Level)
 
 	// 2. 如果启用远程日志，添加远程 Writer
 	if config.RemoteEnabled && config.RemoteUrl != "" {
-		timeout := time.Duration(config.RemoteTimeout) * time.Second
-		remoteWriter = NewRemoteWriter(
-			serviceName,
-			config.RemoteUrl,
-			config.RemoteBatch,
-			timeout,
-		)
-
-		// 添加远程 Writer 到 logx
-		// 注意：go-zero logx 需要通过 writer 方式添加
-		// 这里我们使用 logx.AddWriter 的替代方案
-		setupRemoteWriter(remoteWriter)
+		sink, err := NewSink(SinkConfig{
+			Type:     config.RemoteSink,
+			Url:      config.RemoteUrl,
+			Timeout:  time.Duration(config.RemoteTimeout) * time.Second,
+			Gzip:     config.RemoteGzip,
+			Headers:  config.RemoteHeaders,
+			Username: config.RemoteUsername,
+			Password: config.RemotePassword,
+			Token:    config.RemoteToken,
+			Index:    config.RemoteIndex,
+			Topic:    config.RemoteTopic,
+		})
+		if err != nil {
+			// 远程日志不影响服务启动，只写本地
+			logx.Errorf("远程日志初始化失败: %v", err)
+		} else {
+			sys.remote = NewRemoteWriter(serviceName, RemoteConfig{
+				Sink:          sink,
+				Batch:         config.RemoteBatch,
+				QueueSize:     config.RemoteQueueSize,
+				Overflow:      config.RemoteOverflow,
+				MaxRetries:    config.RemoteMaxRetries,
+				SpoolDir:      config.RemoteSpoolDir,
+				SpoolMaxBytes: int64(config.RemoteSpoolMaxMB) << 20,
+				SpoolMaxFiles: config.RemoteSpoolFiles,
+				Resource:      NewResource(config.ServiceVersion, config.Environment),
+			})
+
+			// 添加远程 Writer 到 logx（与本地 Writer 组合双写）
+			setupRemoteWriter(sys.remote)
+		}
+	}
+
+	// 3. 依次包装本地和远程 Writer：敏感字段脱敏、重复日志折叠、按包/路由覆盖级别过滤
+	installMaskWriter()
+	if config.DedupEnabled {
+		sys.dedup = installDedupWriter(config.DedupRules)
+	}
+	installLevelWriter()
+
+	// slog 及标准库 log 的输出转发到 logx
+	installSlog()
+
+	// 4. 监听日志级别文件
+	if config.LevelFile != "" {
+		watcher, err := WatchLevelFile(config.LevelFile)
+		if err != nil {
+			logx.Errorf("日志级别文件监听失败: %v", err)
+		} else {
+			sys.watcher = watcher
+		}
 	}
 
 	logx.Infof("日志系统初始化完成 [mode=%s, level=%s, remote=%v]",
-		config.Mode, config.Level, config.RemoteEnabled)
+		config.Mode, config.Level, sys.remote != nil)
+	return sys
 }
 
 // setupRemoteWriter 设置远程日志写入器
-// 由于 go-zero logx 的限制，我们需要通过其他方式集成
-func setupRemoteWriter(writer io.Writer) {
-	// go-zero 1.9.x 支持通过环境变量或直接修改内部writer
-	// 这里我们简化处理，在业务代码中可以手动调用 remoteWriter
-	// 或者通过 logx 的 stat/slow log 功能扩展
-
-	// 临时方案：保存 writer 供外部使用
-	// 实际使用时可以包装 logx 的方法
+// logx.AddWriter 将远程 Writer 与 SetUp 创建的文件/控制台 Writer 组合，
+// 业务代码照常调用 logx 即可同时写入本地和远程
+func setupRemoteWriter(writer *RemoteWriter) {
+	logx.AddWriter(newLogxWriter(writer))
+}
+
+// Close 关闭默认实例
+func Close(ctx context.Context) {
+	std.Close(ctx)
 }
 
 // Close 关闭日志系统
-func Close() {
-	if remoteWriter != nil {
-		remoteWriter.Close()
+// 等待远程日志队列排空或 ctx 到期，未发送的日志写入落盘目录
+func (s *System) Close(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	if s.watcher != nil {
+		_ = s.watcher.Close()
+	}
+	// 先输出折叠汇总，确保汇总进入远程队列
+	if s.dedup != nil {
+		s.dedup.shutdown()
+	}
+	if s.remote != nil {
+		if err := s.remote.Close(ctx); err != nil {
+			stats := s.remote.Stats()
+			logx.Errorw("远程日志未完全发送",
+				logx.Field("error", err),
+				logx.Field("spooled", stats.Spooled),
+				logx.Field("dropped", stats.Dropped),
+				LocalOnly(),
+			)
+		}
 	}
 	logx.Close()
 }
 
-// GetRemoteWriter 获取远程日志写入器（供测试使用）
+// RemoteWriter 返回远程日志写入器，未启用远程日志时为 nil
+func (s *System) RemoteWriter() *RemoteWriter {
+	if s == nil {
+		return nil
+	}
+	return s.remote
+}
+
+// FromContext 返回上下文日志记录器（输出到本实例配置的 logx 输出链）
+func (s *System) FromContext(ctx context.Context) *Logger {
+	return FromContext(ctx)
+}
+
+// Default 返回默认实例，未调用 Init 时为 nil
+func Default() *System {
+	return std
+}
+
+// GetRemoteWriter 获取默认实例的远程日志写入器（供测试使用）
 func GetRemoteWriter() *RemoteWriter {
-	return remoteWriter
+	return std.RemoteWriter()
 }
@@ -0,0 +1,126 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// logx 内置字段名
+const (
+	fieldTimestamp = "@timestamp"
+	fieldLevel     = "level"
+	fieldContent   = "content"
+	fieldCaller    = "caller"
+	fieldTrace     = "trace"
+	fieldSpan      = "span"
+
+	// fieldRemote 为 false 时该条日志只写本地（用于远程上报自身的错误日志，避免回环）
+	fieldRemote = "log.remote"
+)
+
+// LocalOnly 标记日志只写本地，不上报远程
+func LocalOnly() logx.LogField {
+	return logx.Field(fieldRemote, false)
+}
+
+// logxWriter 将 logx 日志转发给 RemoteWriter 的 logx.Writer 实现
+// 通过 logx.AddWriter 与本地文件/控制台 Writer 组合，实现双写
+type logxWriter struct {
+	remote *RemoteWriter
+}
+
+// newLogxWriter 创建 logx Writer
+func newLogxWriter(remote *RemoteWriter) logx.Writer {
+	return &logxWriter{remote: remote}
+}
+
+func (w *logxWriter) Alert(v any) {
+	w.write("alert", v)
+}
+
+// Close 远程 Writer 由 log.Close 负责关闭，这里不重复关闭
+func (w *logxWriter) Close() error {
+	return nil
+}
+
+func (w *logxWriter) Debug(v any, fields ...logx.LogField) {
+	w.write("debug", v, fields...)
+}
+
+func (w *logxWriter) Error(v any, fields ...logx.LogField) {
+	w.write("error", v, fields...)
+}
+
+func (w *logxWriter) Info(v any, fields ...logx.LogField) {
+	w.write("info", v, fields...)
+}
+
+func (w *logxWriter) Severe(v any) {
+	w.write("severe", v)
+}
+
+func (w *logxWriter) Slow(v any, fields ...logx.LogField) {
+	w.write("slow", v, fields...)
+}
+
+func (w *logxWriter) Stack(v any) {
+	w.write("error", v)
+}
+
+func (w *logxWriter) Stat(v any, fields ...logx.LogField) {
+	w.write("stat", v, fields...)
+}
+
+// write 构建结构化日志条目并写入远程缓冲区
+func (w *logxWriter) write(level string, v any, fields ...logx.LogField) {
+	entry := LogEntry{
+		Timestamp: time.Now(),
+		Level:     level,
+		Message:   formatContent(v),
+	}
+
+	for _, f := range fields {
+		if !entry.setField(f.Key, f.Value) {
+			return
+		}
+	}
+
+	w.remote.add(entry)
+}
+
+// formatContent 将日志内容转换为字符串
+func formatContent(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
+
+// formatFieldValue 将字段值转换为可 JSON 序列化的形式
+func formatFieldValue(v any) any {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case time.Duration:
+		return val.String()
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return val
+	}
+}
@@ -0,0 +1,47 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+func TestLogxWriter(t *testing.T) {
+	remote := NewRemoteWriter("idrm-api", RemoteConfig{Sink: testSink("http://127.0.0.1:0", time.Second)})
+	defer remote.Close(context.Background())
+
+	w := newLogxWriter(remote)
+	w.Error("查询失败",
+		logx.Field("caller", "logic/category.go:42"),
+		logx.Field("trace", "4bf92f3577b34da6a3ce929d0e0e4736"),
+		logx.Field("span", "00f067aa0ba902b7"),
+		logx.Field("request_id", "req-1"),
+		logx.Field("error", errors.New("db down")),
+		logx.Field("category_id", 7),
+	)
+	w.Error("send remote logs failed", LocalOnly())
+
+	remote.mu.Lock()
+	defer remote.mu.Unlock()
+
+	if len(remote.queue) != 1 {
+		t.Fatalf("buffer len = %d, want 1 (local-only entry must be skipped)", len(remote.queue))
+	}
+
+	entry := remote.queue[0]
+	if entry.Level != "error" || entry.Message != "查询失败" {
+		t.Errorf("entry level/message = %s/%s", entry.Level, entry.Message)
+	}
+	if entry.Caller != "logic/category.go:42" || entry.RequestID != "req-1" {
+		t.Errorf("entry caller/request_id = %s/%s", entry.Caller, entry.RequestID)
+	}
+	if entry.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || entry.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("entry trace/span = %s/%s", entry.TraceID, entry.SpanID)
+	}
+	if entry.Fields["error"] != "db down" || entry.Fields["category_id"] != 7 {
+		t.Errorf("entry fields = %v", entry.Fields)
+	}
+}
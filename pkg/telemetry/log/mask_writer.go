@@ -0,0 +1,86 @@
+package log
+
+import (
+	"time"
+
+	"idrm/pkg/telemetry/mask"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maskWriter 按 mask 键名规则及结构体标签脱敏日志内容和字段的 logx.Writer
+type maskWriter struct {
+	next logx.Writer
+}
+
+// installMaskWriter 用 maskWriter 包装 logx 当前的 Writer（本地和远程均写入脱敏后的内容）
+func installMaskWriter() {
+	if w := logx.Reset(); w != nil {
+		logx.SetWriter(&maskWriter{next: w})
+	}
+}
+
+// maskFields 脱敏字段，无需脱敏时复用原切片
+func maskFields(fields []logx.LogField) []logx.LogField {
+	var out []logx.LogField
+	for i, f := range fields {
+		if mask.KeyRule(f.Key) == "" && isPlain(f.Value) {
+			continue
+		}
+		if out == nil {
+			out = make([]logx.LogField, len(fields))
+			copy(out, fields)
+		}
+		out[i] = logx.Field(f.Key, mask.Field(f.Key, f.Value))
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// isPlain 是否为无需展开的简单值
+func isPlain(v any) bool {
+	switch v.(type) {
+	case nil, string, bool, int, int32, int64, uint, uint32, uint64, float32, float64,
+		error, time.Duration, time.Time:
+		return true
+	}
+	return false
+}
+
+func (w *maskWriter) Alert(v any) {
+	w.next.Alert(v)
+}
+
+func (w *maskWriter) Close() error {
+	return w.next.Close()
+}
+
+func (w *maskWriter) Debug(v any, fields ...logx.LogField) {
+	w.next.Debug(mask.Value(v), maskFields(fields)...)
+}
+
+func (w *maskWriter) Error(v any, fields ...logx.LogField) {
+	w.next.Error(mask.Value(v), maskFields(fields)...)
+}
+
+func (w *maskWriter) Info(v any, fields ...logx.LogField) {
+	w.next.Info(mask.Value(v), maskFields(fields)...)
+}
+
+func (w *maskWriter) Severe(v any) {
+	w.next.Severe(v)
+}
+
+func (w *maskWriter) Slow(v any, fields ...logx.LogField) {
+	w.next.Slow(mask.Value(v), maskFields(fields)...)
+}
+
+func (w *maskWriter) Stack(v any) {
+	w.next.Stack(v)
+}
+
+func (w *maskWriter) Stat(v any, fields ...logx.LogField) {
+	w.next.Stat(mask.Value(v), maskFields(fields)...)
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+func TestMaskFields(t *testing.T) {
+	type login struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+
+	tests := []struct {
+		name   string
+		fields []logx.LogField
+		want   []logx.LogField
+	}{
+		{
+			"普通字段不变",
+			[]logx.LogField{logx.Field("caller", "a.go:1"), logx.Field("status", 200)},
+			[]logx.LogField{logx.Field("caller", "a.go:1"), logx.Field("status", 200)},
+		},
+		{
+			"按键名脱敏",
+			[]logx.LogField{logx.Field("mobile", "13800138000"), logx.Field("token", "abc")},
+			[]logx.LogField{logx.Field("mobile", "138****8000"), logx.Field("token", "******")},
+		},
+		{
+			"结构体字段展开脱敏",
+			[]logx.LogField{logx.Field("req", login{User: "admin", Password: "123456"})},
+			[]logx.LogField{logx.Field("req", map[string]any{"user": "admin", "password": "******"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskFields(tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("maskFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
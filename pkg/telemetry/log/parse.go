@@ -0,0 +1,140 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ansiColor 终端颜色控制符（plain 编码在终端输出时 level 带颜色）
+var ansiColor = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// parseLogEntries 解析 go-zero 输出的日志，一行一条
+// 支持 JSON 编码和 plain 编码，无法识别的行整体作为 info 日志的内容
+func parseLogEntries(p []byte) []LogEntry {
+	var entries []LogEntry
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		entry, ok := parseLine(line)
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// parseLine 解析单行日志，标记为只写本地的日志返回 false
+func parseLine(line []byte) (LogEntry, bool) {
+	if line[0] == '{' {
+		if entry, ok, err := parseJSON(line); err == nil {
+			return entry, ok
+		}
+	}
+	return parsePlain(string(line))
+}
+
+// parseJSON 解析 JSON 编码：{"@timestamp":"...","level":"info","content":"...","caller":"...",...}
+func parseJSON(line []byte) (LogEntry, bool, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return LogEntry{}, false, err
+	}
+
+	entry := LogEntry{Level: "info"}
+	for key, value := range raw {
+		switch key {
+		case fieldTimestamp:
+			entry.Timestamp = parseTimestamp(fmt.Sprint(value))
+		case fieldLevel:
+			entry.Level = normalizeLevel(fmt.Sprint(value))
+		case fieldContent:
+			entry.Message = formatContent(value)
+		default:
+			if !entry.setField(key, value) {
+				return LogEntry{}, false, nil
+			}
+		}
+	}
+	return entry, true, nil
+}
+
+// parsePlain 解析 plain 编码：时间戳\t级别\t内容\tkey=value...
+func parsePlain(line string) (LogEntry, bool) {
+	parts := strings.Split(line, "\t")
+	if len(parts) < 3 {
+		return LogEntry{Level: "info", Message: line}, true
+	}
+
+	ts := parseTimestamp(parts[0])
+	if ts.IsZero() {
+		return LogEntry{Level: "info", Message: line}, true
+	}
+
+	entry := LogEntry{
+		Timestamp: ts,
+		Level:     normalizeLevel(parts[1]),
+		Message:   parts[2],
+	}
+	for _, item := range parts[3:] {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			entry.Message += "\t" + item
+			continue
+		}
+		if !entry.setField(key, value) {
+			return LogEntry{}, false
+		}
+	}
+	return entry, true
+}
+
+// parseTimestamp 解析 go-zero 时间戳（默认格式 2006-01-02T15:04:05.000Z07:00），失败返回零值
+func parseTimestamp(s string) time.Time {
+	ts, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}
+	}
+	return ts
+}
+
+// normalizeLevel 去除颜色和空白并转小写
+func normalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(ansiColor.ReplaceAllString(level, "")))
+	if level == "" {
+		return "info"
+	}
+	return level
+}
+
+// setField 将日志字段写入条目，内置字段写入对应属性，其余写入 Fields
+// 字段标记为只写本地时返回 false
+func (e *LogEntry) setField(key string, value any) bool {
+	switch key {
+	case fieldRemote:
+		// logx 直接传入 bool，plain 编码下为字符串
+		if fmt.Sprint(value) == "false" {
+			return false
+		}
+	case fieldCaller:
+		e.Caller = fmt.Sprint(value)
+	case fieldTrace:
+		e.TraceID = fmt.Sprint(value)
+	case fieldSpan:
+		e.SpanID = fmt.Sprint(value)
+	case FieldRequestID:
+		e.RequestID = fmt.Sprint(value)
+	default:
+		if e.Fields == nil {
+			e.Fields = make(map[string]interface{})
+		}
+		e.Fields[key] = formatFieldValue(value)
+	}
+	return true
+}
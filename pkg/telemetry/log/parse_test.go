@@ -0,0 +1,93 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogEntries(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123000000, time.FixedZone("", 8*3600))
+
+	tests := []struct {
+		name  string
+		input string
+		want  []LogEntry
+	}{
+		{
+			name:  "JSON编码",
+			input: `{"@timestamp":"2024-05-06T07:08:09.123+08:00","level":"error","content":"查询失败","caller":"logic/category.go:42","trace":"4bf92f3577b34da6a3ce929d0e0e4736","span":"00f067aa0ba902b7","request_id":"req-1","category_id":7}`,
+			want: []LogEntry{{
+				Timestamp: ts,
+				Level:     "error",
+				Message:   "查询失败",
+				Caller:    "logic/category.go:42",
+				TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:    "00f067aa0ba902b7",
+				RequestID: "req-1",
+				Fields:    map[string]interface{}{"category_id": float64(7)},
+			}},
+		},
+		{
+			name:  "plain编码",
+			input: "2024-05-06T07:08:09.123+08:00\tslow\tslow query\tcaller=db/trace.go:76\ttrace=abc\tduration_ms=230\n",
+			want: []LogEntry{{
+				Timestamp: ts,
+				Level:     "slow",
+				Message:   "slow query",
+				Caller:    "db/trace.go:76",
+				TraceID:   "abc",
+				Fields:    map[string]interface{}{"duration_ms": "230"},
+			}},
+		},
+		{
+			name:  "plain编码带颜色",
+			input: "2024-05-06T07:08:09.123+08:00\t\x1b[34m info \x1b[0m\t服务启动",
+			want:  []LogEntry{{Timestamp: ts, Level: "info", Message: "服务启动"}},
+		},
+		{
+			name:  "多行",
+			input: "2024-05-06T07:08:09.123+08:00\tinfo\ta\n2024-05-06T07:08:09.123+08:00\terror\tb\n",
+			want: []LogEntry{
+				{Timestamp: ts, Level: "info", Message: "a"},
+				{Timestamp: ts, Level: "error", Message: "b"},
+			},
+		},
+		{
+			name:  "只写本地",
+			input: `{"@timestamp":"2024-05-06T07:08:09.123+08:00","level":"error","content":"send failed","log.remote":false}`,
+			want:  nil,
+		},
+		{
+			name:  "无法识别",
+			input: "panic: runtime error",
+			want:  []LogEntry{{Level: "info", Message: "panic: runtime error"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLogEntries([]byte(tt.input))
+			if len(got) != len(tt.want) {
+				t.Fatalf("len = %d, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if !g.Timestamp.Equal(w.Timestamp) {
+					t.Errorf("[%d] timestamp = %v, want %v", i, g.Timestamp, w.Timestamp)
+				}
+				if g.Level != w.Level || g.Message != w.Message || g.Caller != w.Caller ||
+					g.TraceID != w.TraceID || g.SpanID != w.SpanID || g.RequestID != w.RequestID {
+					t.Errorf("[%d] entry = %+v, want %+v", i, g, w)
+				}
+				if len(g.Fields) != len(w.Fields) {
+					t.Errorf("[%d] fields = %v, want %v", i, g.Fields, w.Fields)
+				}
+				for k, v := range w.Fields {
+					if g.Fields[k] != v {
+						t.Errorf("[%d] fields[%s] = %v, want %v", i, k, g.Fields[k], v)
+					}
+				}
+			}
+		})
+	}
+}
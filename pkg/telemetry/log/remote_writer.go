@@ -1,177 +1,495 @@
 package log
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"net/http"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+// 队列溢出策略
+const (
+	OverflowDropOldest = "drop_oldest" // 丢弃最早的日志
+	OverflowDropNewest = "drop_newest" // 丢弃新写入的日志
+	OverflowBlock      = "block"       // 阻塞写入方直到队列有空位
+)
+
+// spoolExt 落盘批次文件扩展名
+const spoolExt = ".json"
+
+// RemoteConfig 远程日志写入器配置
+type RemoteConfig struct {
+	Sink          Sink          // 投递目标
+	Batch         int           // 批量发送数量
+	FlushInterval time.Duration // 定时发送间隔
+	QueueSize     int           // 内存队列容量(条)
+	Overflow      string        // 队列满时的策略: drop_oldest/drop_newest/block
+	MaxRetries    int           // 发送失败重试次数
+	RetryBackoff  time.Duration // 首次重试间隔，之后指数递增
+	MaxBackoff    time.Duration // 重试间隔上限
+	SpoolDir      string        // 落盘目录，为空时重试耗尽的批次直接丢弃
+	SpoolMaxBytes int64         // 落盘目录容量上限(字节)，超出时删除最早的批次
+	SpoolMaxFiles int           // 落盘文件数上限，超出时删除最早的批次
+	Resource      Resource      // 附加到每条日志的资源信息
+}
+
+// RemoteStats 远程日志写入器计数
+type RemoteStats struct {
+	Sent    int64 `json:"sent"`    // 已成功发送条数
+	Dropped int64 `json:"dropped"` // 丢弃条数（队列溢出、重试耗尽且未落盘、落盘超出上限、关闭后写入）
+	Retried int64 `json:"retried"` // 批次重试次数
+	Spooled int64 `json:"spooled"` // 写入落盘目录的条数（重放成功后计入 Sent）
+	Queued  int64 `json:"queued"`  // 当前队列中的条数
+}
+
 // RemoteWriter 远程日志写入器
+// 日志先进入有界内存队列，由单个发送协程批量发送；
+// 发送失败按指数退避重试，重试耗尽后写入有界落盘目录，下次启动或发送恢复后重放
 type RemoteWriter struct {
 	serviceName string
-	url         string
-	client      *http.Client
-	batchSize   int
-	buffer      []LogEntry
-	mu          sync.Mutex
-	closeChan   chan struct{}
+	config      RemoteConfig
+	sink        Sink
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []LogEntry
+	closed bool
+
+	notify  chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+
+	// ctx 在 Close 超时后取消，中断进行中的发送
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	closeOnce sync.Once
+	spoolSeq  atomic.Int64
+
+	sent    atomic.Int64
+	dropped atomic.Int64
+	retried atomic.Int64
+	spooled atomic.Int64
 }
 
 // LogEntry 日志条目
 type LogEntry struct {
-	Timestamp   int64                  `json:"timestamp"`
+	Timestamp   time.Time              `json:"timestamp"` // RFC3339Nano
 	Level       string                 `json:"level"`
 	Message     string                 `json:"message"`
 	ServiceName string                 `json:"service_name"`
+	Caller      string                 `json:"caller,omitempty"`
 	TraceID     string                 `json:"trace_id,omitempty"`
 	SpanID      string                 `json:"span_id,omitempty"`
+	RequestID   string                 `json:"request_id,omitempty"`
+	Resource    Resource               `json:"resource"`
 	Fields      map[string]interface{} `json:"fields,omitempty"`
 }
 
 // NewRemoteWriter 创建远程日志写入器
-func NewRemoteWriter(serviceName, url string, batchSize int, timeout time.Duration) *RemoteWriter {
+func NewRemoteWriter(serviceName string, config RemoteConfig) *RemoteWriter {
+	config = withRemoteDefaults(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
 	rw := &RemoteWriter{
 		serviceName: serviceName,
-		url:         url,
-		batchSize:   batchSize,
-		buffer:      make([]LogEntry, 0, batchSize),
-		closeChan:   make(chan struct{}),
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		config:      config,
+		queue:       make([]LogEntry, 0, config.Batch),
+		notify:      make(chan struct{}, 1),
+		closing:     make(chan struct{}),
+		done:        make(chan struct{}),
+		sink:        config.Sink,
+		ctx:         ctx,
+		cancel:      cancel,
 	}
+	rw.cond = sync.NewCond(&rw.mu)
 
-	// 启动定时发送
-	go rw.flushLoop()
+	if config.SpoolDir != "" {
+		if err := os.MkdirAll(config.SpoolDir, 0o755); err != nil {
+			logx.Errorw("create remote log spool dir failed",
+				logx.Field("dir", config.SpoolDir), logx.Field("error", err), LocalOnly())
+			rw.config.SpoolDir = ""
+		}
+	}
+
+	// 启动发送协程
+	go rw.run()
 
 	return rw
 }
 
-// Write 实现 io.Writer 接口
+// withRemoteDefaults 填充配置默认值
+func withRemoteDefaults(c RemoteConfig) RemoteConfig {
+	if c.Batch <= 0 {
+		c.Batch = 100
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = 3 * time.Second
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 10000
+	}
+	switch c.Overflow {
+	case OverflowDropOldest, OverflowDropNewest, OverflowBlock:
+	default:
+		c.Overflow = OverflowDropOldest
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.MaxBackoff < c.RetryBackoff {
+		c.MaxBackoff = c.RetryBackoff
+	}
+	if c.SpoolMaxBytes <= 0 {
+		c.SpoolMaxBytes = 100 << 20
+	}
+	if c.SpoolMaxFiles <= 0 {
+		c.SpoolMaxFiles = 1000
+	}
+	return c
+}
+
+// Write 实现 io.Writer 接口，解析 go-zero 的 JSON 或 plain 编码日志
 func (w *RemoteWriter) Write(p []byte) (n int, err error) {
-	// 解析日志内容并添加到缓冲区
-	entry := w.parseLogEntry(p)
+	for _, entry := range parseLogEntries(p) {
+		w.add(entry)
+	}
+
+	return len(p), nil
+}
+
+// add 添加日志条目到队列，队列满时按溢出策略处理，达到批量大小时通知发送
+func (w *RemoteWriter) add(entry LogEntry) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.ServiceName = w.serviceName
+	entry.Resource = w.config.Resource
 
 	w.mu.Lock()
-	w.buffer = append(w.buffer, entry)
-	shouldFlush := len(w.buffer) >= w.batchSize
+	for !w.closed && len(w.queue) >= w.config.QueueSize {
+		switch w.config.Overflow {
+		case OverflowBlock:
+			w.cond.Wait()
+			continue
+		case OverflowDropNewest:
+			w.mu.Unlock()
+			w.dropped.Add(1)
+			return
+		default:
+			w.queue = w.queue[1:]
+			w.dropped.Add(1)
+		}
+	}
+	if w.closed {
+		w.mu.Unlock()
+		w.dropped.Add(1)
+		return
+	}
+	w.queue = append(w.queue, entry)
+	shouldFlush := len(w.queue) >= w.config.Batch
 	w.mu.Unlock()
 
 	if shouldFlush {
-		w.flush()
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
 	}
+}
 
-	return len(p), nil
+// take 从队列头部取出最多 n 条日志
+func (w *RemoteWriter) take(n int) []LogEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.queue) == 0 {
+		return nil
+	}
+	if n > len(w.queue) {
+		n = len(w.queue)
+	}
+	batch := make([]LogEntry, n)
+	copy(batch, w.queue)
+	w.queue = append(w.queue[:0], w.queue[n:]...)
+	w.cond.Broadcast()
+	return batch
 }
 
-// flush 发送日志到远程服务器
+// run 发送协程：先重放落盘批次，之后按批量或定时发送，关闭时排空队列
+func (w *RemoteWriter) run() {
+	defer close(w.done)
+
+	w.replaySpool()
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.notify:
+			w.flush()
+		case <-ticker.C:
+			w.flush()
+			w.replaySpool()
+		case <-w.closing:
+			w.flush()
+			return
+		}
+	}
+}
+
+// flush 发送队列中的全部日志
 func (w *RemoteWriter) flush() {
-	w.mu.Lock()
-	if len(w.buffer) == 0 {
-		w.mu.Unlock()
+	for {
+		batch := w.take(w.config.Batch)
+		if len(batch) == 0 {
+			return
+		}
+		w.deliver(batch)
+	}
+}
+
+// deliver 发送一个批次，失败按指数退避重试，重试耗尽后落盘
+func (w *RemoteWriter) deliver(batch []LogEntry) {
+	backoff := w.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := w.sink.Send(w.ctx, batch)
+		if err == nil {
+			w.sent.Add(int64(len(batch)))
+			return
+		}
+		if attempt >= w.config.MaxRetries || w.ctx.Err() != nil {
+			logx.Errorw("send remote logs failed",
+				logx.Field("error", err), logx.Field("attempts", attempt+1), LocalOnly())
+			break
+		}
+
+		w.retried.Add(1)
+		if !w.sleep(jitter(backoff)) {
+			break
+		}
+		backoff *= 2
+		if backoff > w.config.MaxBackoff {
+			backoff = w.config.MaxBackoff
+		}
+	}
+
+	if err := w.spool(batch); err != nil {
+		w.dropped.Add(int64(len(batch)))
+		if w.config.SpoolDir != "" {
+			logx.Errorw("spool remote logs failed", logx.Field("error", err), LocalOnly())
+		}
 		return
 	}
+	w.spooled.Add(int64(len(batch)))
+}
 
-	logs := make([]LogEntry, len(w.buffer))
-	copy(logs, w.buffer)
-	w.buffer = w.buffer[:0]
-	w.mu.Unlock()
+// sleep 等待重试间隔，Close 超时取消时提前返回 false
+func (w *RemoteWriter) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	// 异步发送到远程
-	go w.send(logs)
+	select {
+	case <-timer.C:
+		return true
+	case <-w.ctx.Done():
+		return false
+	}
 }
 
-// send 发送日志（异步）
-func (w *RemoteWriter) send(logs []LogEntry) {
-	data, err := json.Marshal(map[string]interface{}{
-		"logs": logs,
-	})
+// jitter 在退避间隔基础上增加 ±20% 的随机抖动，避免多实例同时重试
+func jitter(d time.Duration) time.Duration {
+	delta := float64(d) * 0.2
+	return d + time.Duration(delta*(2*rand.Float64()-1))
+}
+
+// errSpoolDisabled 未配置落盘目录
+var errSpoolDisabled = errors.New("remote log spool disabled")
+
+// spool 将批次写入落盘目录，先写临时文件再重命名，避免重放读到半个文件
+func (w *RemoteWriter) spool(batch []LogEntry) error {
+	if w.config.SpoolDir == "" {
+		return errSpoolDisabled
+	}
+
+	data, err := json.Marshal(batch)
 	if err != nil {
-		logx.Errorf("marshal remote logs failed: %v", err)
-		return
+		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// 文件名按时间排序，保证重放顺序与写入顺序一致
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), w.spoolSeq.Add(1)%1000000, spoolExt)
+	tmp := filepath.Join(w.config.SpoolDir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(w.config.SpoolDir, name)); err != nil {
+		return err
+	}
+	w.trimSpool()
+	return nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(data))
+// trimSpool 落盘目录超出容量或文件数上限时删除最早的批次，删除的条数计入 Dropped
+func (w *RemoteWriter) trimSpool() {
+	files, err := spoolFiles(w.config.SpoolDir)
 	if err != nil {
-		logx.Errorf("create remote log request failed: %v", err)
+		logx.Errorw("list remote log spool failed", logx.Field("error", err), LocalOnly())
 		return
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := w.client.Do(req)
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			sizes[i] = info.Size()
+			total += sizes[i]
+		}
+	}
+
+	var removed, dropped int64
+	for i := 0; i < len(files) && (len(files)-i > w.config.SpoolMaxFiles || total > w.config.SpoolMaxBytes); i++ {
+		n := spoolEntries(files[i])
+		if err := os.Remove(files[i]); err != nil {
+			logx.Errorw("remove remote log spool file failed",
+				logx.Field("file", files[i]), logx.Field("error", err), LocalOnly())
+			return
+		}
+		total -= sizes[i]
+		removed++
+		dropped += n
+	}
+	if removed > 0 {
+		w.dropped.Add(dropped)
+		logx.Errorw("remote log spool full, oldest batches dropped",
+			logx.Field("files", removed), logx.Field("entries", dropped), LocalOnly())
+	}
+}
+
+// spoolEntries 批次文件中的日志条数，无法解析时返回 0
+func spoolEntries(file string) int64 {
+	data, err := os.ReadFile(file)
 	if err != nil {
-		logx.Errorf("send remote logs failed: %v", err)
+		return 0
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return 0
+	}
+	return int64(len(batch))
+}
+
+// replaySpool 按写入顺序重放落盘批次，遇到发送失败即停止，等待下次重放
+func (w *RemoteWriter) replaySpool() {
+	if w.config.SpoolDir == "" {
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		logx.Errorf("remote log server returned status: %d", resp.StatusCode)
+	files, err := spoolFiles(w.config.SpoolDir)
+	if err != nil {
+		logx.Errorw("list remote log spool failed", logx.Field("error", err), LocalOnly())
+		return
 	}
-}
 
-// flushLoop 定时刷新
-func (w *RemoteWriter) flushLoop() {
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
+	for _, file := range files {
+		if w.ctx.Err() != nil {
+			return
+		}
 
-	for {
-		select {
-		case <-ticker.C:
-			w.flush()
-		case <-w.closeChan:
-			w.flush()
+		data, err := os.ReadFile(file)
+		if err != nil {
+			logx.Errorw("read remote log spool failed",
+				logx.Field("file", file), logx.Field("error", err), LocalOnly())
 			return
 		}
+
+		var batch []LogEntry
+		if err := json.Unmarshal(data, &batch); err != nil {
+			// 损坏的文件无法重放，丢弃避免阻塞后续批次
+			logx.Errorw("corrupted remote log spool file removed",
+				logx.Field("file", file), logx.Field("error", err), LocalOnly())
+			_ = os.Remove(file)
+			continue
+		}
+
+		if err := w.sink.Send(w.ctx, batch); err != nil {
+			return
+		}
+		_ = os.Remove(file)
+		w.sent.Add(int64(len(batch)))
 	}
 }
 
-// Close 关闭
-func (w *RemoteWriter) Close() error {
-	close(w.closeChan)
-	time.Sleep(100 * time.Millisecond) // 等待最后一次flush
-	return nil
-}
+// spoolFiles 列出落盘目录中的批次文件（按文件名排序）
+func spoolFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
 
-// parseLogEntry 解析日志条目
-func (w *RemoteWriter) parseLogEntry(p []byte) LogEntry {
-	// 简单解析，实际可以更复杂
-	entry := LogEntry{
-		Timestamp:   time.Now().Unix(),
-		Message:     string(p),
-		ServiceName: w.serviceName,
-		Fields:      make(map[string]interface{}),
-	}
-
-	// 尝试从消息中提取level
-	msg := string(p)
-	if len(msg) > 0 {
-		// 简单判断（实际应该解析JSON格式的logx输出）
-		switch {
-		case contains(msg, "ERROR"):
-			entry.Level = "error"
-		case contains(msg, "WARN"):
-			entry.Level = "warn"
-		case contains(msg, "INFO"):
-			entry.Level = "info"
-		case contains(msg, "DEBUG"):
-			entry.Level = "debug"
-		default:
-			entry.Level = "info"
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), spoolExt) {
+			continue
 		}
+		files = append(files, filepath.Join(dir, e.Name()))
 	}
+	sort.Strings(files)
+	return files, nil
+}
 
-	return entry
+// Stats 返回发送计数
+func (w *RemoteWriter) Stats() RemoteStats {
+	w.mu.Lock()
+	queued := len(w.queue)
+	w.mu.Unlock()
+
+	return RemoteStats{
+		Sent:    w.sent.Load(),
+		Dropped: w.dropped.Load(),
+		Retried: w.retried.Load(),
+		Spooled: w.spooled.Load(),
+		Queued:  int64(queued),
+	}
 }
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr))
+// Close 关闭写入器，等待队列排空或 ctx 到期
+// 到期后中断进行中的发送，剩余日志写入落盘目录（未配置时丢弃）
+func (w *RemoteWriter) Close(ctx context.Context) error {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.cond.Broadcast()
+		w.mu.Unlock()
+		close(w.closing)
+	})
+
+	var err error
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		w.cancel()
+		<-w.done
+		err = ctx.Err()
+	}
+	w.cancel()
+
+	if closeErr := w.sink.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
@@ -0,0 +1,228 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// collector 测试用日志接收端，前 failures 次请求返回 503
+type collector struct {
+	failures atomic.Int64
+	mu       sync.Mutex
+	received []LogEntry
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.failures.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var body struct {
+		Logs []LogEntry `json:"logs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.received = append(c.received, body.Logs...)
+	c.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// testSink 创建发往 url 的通用 HTTP 投递目标
+func testSink(url string, timeout time.Duration) Sink {
+	sink, _ := NewSink(SinkConfig{Url: url, Timeout: timeout})
+	return sink
+}
+
+func (c *collector) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msgs := make([]string, len(c.received))
+	for i, e := range c.received {
+		msgs[i] = e.Message
+	}
+	return msgs
+}
+
+func TestRemoteWriterOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		overflow string
+		want     []string
+	}{
+		{"丢弃最早", OverflowDropOldest, []string{"c", "d"}},
+		{"丢弃最新", OverflowDropNewest, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &collector{}
+			srv := httptest.NewServer(c)
+			defer srv.Close()
+
+			// 批量大于队列容量，保证关闭前不会触发发送
+			w := NewRemoteWriter("idrm-api", RemoteConfig{
+				Sink:          testSink(srv.URL, 0),
+				Batch:         10,
+				QueueSize:     2,
+				FlushInterval: time.Hour,
+				Overflow:      tt.overflow,
+			})
+			for _, msg := range []string{"a", "b", "c", "d"} {
+				w.add(LogEntry{Message: msg})
+			}
+			if err := w.Close(context.Background()); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := c.messages()
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("received = %v, want %v", got, tt.want)
+			}
+			if stats := w.Stats(); stats.Dropped != 2 || stats.Sent != 2 {
+				t.Errorf("stats = %+v, want dropped=2 sent=2", stats)
+			}
+		})
+	}
+}
+
+func TestRemoteWriterRetry(t *testing.T) {
+	c := &collector{}
+	c.failures.Store(2)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	w := NewRemoteWriter("idrm-api", RemoteConfig{
+		Sink:         testSink(srv.URL, 0),
+		MaxRetries:   3,
+		RetryBackoff: time.Millisecond,
+	})
+	w.add(LogEntry{Message: "a"})
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if stats := w.Stats(); stats.Sent != 1 || stats.Retried != 2 || stats.Dropped != 0 {
+		t.Errorf("stats = %+v, want sent=1 retried=2 dropped=0", stats)
+	}
+}
+
+func TestRemoteWriterSpoolReplay(t *testing.T) {
+	dir := t.TempDir()
+	c := &collector{}
+	c.failures.Store(1 << 30)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	// 远程不可用：重试耗尽后落盘
+	w := NewRemoteWriter("idrm-api", RemoteConfig{
+		Sink:         testSink(srv.URL, 0),
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+		SpoolDir:     dir,
+	})
+	w.add(LogEntry{Message: "a"})
+	w.add(LogEntry{Message: "b"})
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if stats := w.Stats(); stats.Spooled != 2 || stats.Sent != 0 {
+		t.Fatalf("stats = %+v, want spooled=2 sent=0", stats)
+	}
+
+	// 远程恢复：新实例启动时重放
+	c.failures.Store(0)
+	w = NewRemoteWriter("idrm-api", RemoteConfig{Sink: testSink(srv.URL, 0), SpoolDir: dir})
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got := c.messages(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("received = %v, want [a b]", got)
+	}
+	if files, _ := spoolFiles(dir); len(files) != 0 {
+		t.Errorf("spool files = %v, want empty", files)
+	}
+}
+
+func TestRemoteWriterCloseDeadline(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	dir := t.TempDir()
+	w := NewRemoteWriter("idrm-api", RemoteConfig{Sink: testSink(srv.URL, time.Minute), SpoolDir: dir})
+	w.add(LogEntry{Message: "a"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Close() error = %v, want DeadlineExceeded", err)
+	}
+	if stats := w.Stats(); stats.Spooled != 1 {
+		t.Errorf("stats = %+v, want spooled=1", stats)
+	}
+}
+
+func TestRemoteWriterSpoolLimit(t *testing.T) {
+	batch := []LogEntry{{Message: "a"}, {Message: "b"}}
+	data, _ := json.Marshal(batch)
+
+	tests := []struct {
+		name        string
+		maxBytes    int64
+		maxFiles    int
+		wantFiles   int
+		wantDropped int64
+	}{
+		{name: "超出文件数删除最早批次", maxFiles: 2, wantFiles: 2, wantDropped: 2},
+		{name: "超出容量删除最早批次", maxBytes: int64(len(data)) * 3 / 2, wantFiles: 1, wantDropped: 4},
+		{name: "未超出上限", maxFiles: 5, wantFiles: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			c := &collector{}
+			c.failures.Store(1 << 30)
+			srv := httptest.NewServer(c)
+			defer srv.Close()
+
+			w := NewRemoteWriter("idrm-api", RemoteConfig{
+				Sink:          testSink(srv.URL, 0),
+				SpoolDir:      dir,
+				SpoolMaxBytes: tt.maxBytes,
+				SpoolMaxFiles: tt.maxFiles,
+			})
+			defer w.Close(context.Background())
+
+			for i := 0; i < 3; i++ {
+				if err := w.spool(batch); err != nil {
+					t.Fatalf("spool() error = %v", err)
+				}
+			}
+
+			files, _ := spoolFiles(dir)
+			if len(files) != tt.wantFiles {
+				t.Errorf("spool files = %d, want %d", len(files), tt.wantFiles)
+			}
+			if got := w.Stats().Dropped; got != tt.wantDropped {
+				t.Errorf("dropped = %d, want %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
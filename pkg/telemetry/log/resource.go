@@ -0,0 +1,35 @@
+package log
+
+import (
+	"fmt"
+	"os"
+)
+
+// envInstanceID 实例 ID 环境变量（如 K8s 中通过 Downward API 注入 Pod 名称）
+const envInstanceID = "INSTANCE_ID"
+
+// Resource 日志资源信息，附加到每条远程日志，用于区分版本、环境和实例
+type Resource struct {
+	ServiceVersion string `json:"service_version,omitempty"`
+	Environment    string `json:"environment,omitempty"`
+	Hostname       string `json:"hostname,omitempty"`
+	InstanceID     string `json:"instance_id,omitempty"`
+}
+
+// NewResource 创建资源信息
+// 实例 ID 优先读取 INSTANCE_ID 环境变量，未设置时使用 主机名-进程号
+func NewResource(serviceVersion, environment string) Resource {
+	hostname, _ := os.Hostname()
+
+	instanceID := os.Getenv(envInstanceID)
+	if instanceID == "" {
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return Resource{
+		ServiceVersion: serviceVersion,
+		Environment:    environment,
+		Hostname:       hostname,
+		InstanceID:     instanceID,
+	}
+}
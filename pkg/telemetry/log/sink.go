@@ -0,0 +1,169 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 远程日志投递目标类型
+const (
+	SinkHTTP          = "http"          // 通用 HTTP：{"logs": [...]}
+	SinkLoki          = "loki"          // Grafana Loki push API
+	SinkElasticsearch = "elasticsearch" // Elasticsearch/OpenSearch _bulk
+	SinkSyslog        = "syslog"        // RFC 5424 syslog（TCP/UDP）
+	SinkKafka         = "kafka"         // Kafka
+)
+
+// Sink 远程日志投递目标
+// Send 返回错误时由 RemoteWriter 负责重试和落盘
+type Sink interface {
+	Send(ctx context.Context, entries []LogEntry) error
+	Close() error
+}
+
+// SinkConfig 投递目标配置
+type SinkConfig struct {
+	Type    string            // http/loki/elasticsearch/syslog/kafka
+	Url     string            // HTTP 类为接收地址；syslog 为 tcp://host:514 或 udp://host:514；kafka 为逗号分隔的 broker 列表
+	Timeout time.Duration     // 单次请求超时
+	Gzip    bool              // 压缩请求体（kafka 为 gzip 消息压缩，syslog 不支持）
+	Headers map[string]string // 附加请求头（HTTP 类）
+
+	// 认证：HTTP 类使用 Basic 或 Bearer，kafka 使用 SASL/PLAIN，syslog 不支持
+	Username string
+	Password string
+	Token    string
+
+	Index string // elasticsearch 索引前缀，按天滚动：<Index>-2006.01.02
+	Topic string // kafka topic
+}
+
+// NewSink 按类型创建投递目标
+func NewSink(config SinkConfig) (Sink, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+
+	switch strings.ToLower(config.Type) {
+	case "", SinkHTTP:
+		return newHTTPSink(config, "application/json", encodeGeneric), nil
+	case SinkLoki:
+		return newHTTPSink(config, "application/json", encodeLoki), nil
+	case SinkElasticsearch:
+		return newElasticsearchSink(config), nil
+	case SinkSyslog:
+		return newSyslogSink(config)
+	case SinkKafka:
+		return newKafkaSink(config)
+	default:
+		return nil, fmt.Errorf("unknown log sink type: %s", config.Type)
+	}
+}
+
+// httpSink 基于 HTTP POST 的投递目标，不同协议只在请求体编码上不同
+type httpSink struct {
+	config      SinkConfig
+	client      *http.Client
+	contentType string
+	encode      func([]LogEntry) ([]byte, error)
+	// check 检查 2xx 响应体，用于 _bulk 这类部分失败仍返回 200 的接口
+	check func(body []byte) error
+}
+
+// newHTTPSink 创建 HTTP 投递目标
+func newHTTPSink(config SinkConfig, contentType string, encode func([]LogEntry) ([]byte, error)) *httpSink {
+	return &httpSink{
+		config:      config,
+		client:      &http.Client{Timeout: config.Timeout},
+		contentType: contentType,
+		encode:      encode,
+	}
+}
+
+// Send 编码并发送一个批次，非 2xx 状态视为失败
+func (s *httpSink) Send(ctx context.Context, entries []LogEntry) error {
+	data, err := s.encode(entries)
+	if err != nil {
+		return fmt.Errorf("encode logs: %w", err)
+	}
+
+	body, err := compressBody(data, s.config.Gzip)
+	if err != nil {
+		return fmt.Errorf("compress logs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Url, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	if s.config.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	setAuth(req, s.config)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("remote log server returned status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	if s.check != nil {
+		return s.check(respBody)
+	}
+	return nil
+}
+
+// Close HTTP 投递目标无需关闭
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// encodeGeneric 通用格式：{"logs": [...]}
+func encodeGeneric(entries []LogEntry) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"logs": entries,
+	})
+}
+
+// compressBody 按需 gzip 压缩请求体
+func compressBody(data []byte, compress bool) (io.Reader, error) {
+	if !compress {
+		return bytes.NewReader(data), nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// setAuth 设置认证及附加请求头
+func setAuth(req *http.Request, config SinkConfig) {
+	switch {
+	case config.Token != "":
+		req.Header.Set("Authorization", "Bearer "+config.Token)
+	case config.Username != "":
+		req.SetBasicAuth(config.Username, config.Password)
+	}
+	for k, v := range config.Headers {
+		req.Header.Set(k, v)
+	}
+}
@@ -0,0 +1,93 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// defaultIndex elasticsearch 默认索引前缀
+const defaultIndex = "idrm-logs"
+
+// newElasticsearchSink 创建 Elasticsearch/OpenSearch _bulk 投递目标
+// Url 为集群地址（如 http://es:9200），自动补全 /_bulk
+func newElasticsearchSink(config SinkConfig) *httpSink {
+	if !strings.HasSuffix(config.Url, "/_bulk") {
+		config.Url = strings.TrimSuffix(config.Url, "/") + "/_bulk"
+	}
+	index := config.Index
+	if index == "" {
+		index = defaultIndex
+	}
+
+	sink := newHTTPSink(config, "application/x-ndjson", func(entries []LogEntry) ([]byte, error) {
+		return encodeBulk(index, entries)
+	})
+	sink.check = checkBulkResponse
+	return sink
+}
+
+// encodeBulk 编码 _bulk 请求体：每条日志一行 action、一行文档，按天滚动索引
+func encodeBulk(index string, entries []LogEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		action := map[string]map[string]string{
+			"create": {"_index": index + "-" + entry.Timestamp.Format("2006.01.02")},
+		}
+		if err := writeJSONLine(&buf, action); err != nil {
+			return nil, err
+		}
+		if err := writeJSONLine(&buf, entry); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSONLine 写入一行 JSON
+func writeJSONLine(buf *bytes.Buffer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	buf.WriteByte('\n')
+	return nil
+}
+
+// checkBulkResponse _bulk 部分失败时仍返回 200，需检查 errors 字段
+// 文档没有固定 _id，整批重试会导致已成功的文档重复写入，因此部分失败只记录本地日志
+func checkBulkResponse(body []byte) error {
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Error any `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || !resp.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first any
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error != nil {
+				if failed == 0 {
+					first = result.Error
+				}
+				failed++
+			}
+		}
+	}
+	logx.Errorw("elasticsearch bulk partially failed",
+		logx.Field("failed", failed),
+		logx.Field("total", len(resp.Items)),
+		logx.Field("error", fmt.Sprint(first)),
+		LocalOnly(),
+	)
+	return nil
+}
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"idrm/pkg/errorx"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeKafkaWriter 替代 *kafka.Writer，记录写入的消息
+type fakeKafkaWriter struct {
+	messages []kafka.Message
+	err      error
+	closed   bool
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestKafkaSink(t *testing.T) {
+	writeErr := errors.New("kafka: leader not available")
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "每条日志一条消息", err: nil},
+		{name: "写入失败包装为Kafka错误码", err: writeErr, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fakeKafkaWriter{err: tt.err}
+			sink := &kafkaSink{writer: w}
+
+			err := sink.Send(context.Background(), testEntries)
+			if tt.wantErr {
+				codeErr, ok := errorx.FromError(err)
+				if !ok || codeErr.Code != errorx.ErrCodeKafka || !errors.Is(err, writeErr) {
+					t.Fatalf("Send() error = %v, want ErrCodeKafka wrapping %v", err, writeErr)
+				}
+				if strings.Count(err.Error(), writeErr.Error()) != 1 {
+					t.Errorf("Send() error = %q, want cause once", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if len(w.messages) != len(testEntries) {
+				t.Fatalf("messages = %d, want %d", len(w.messages), len(testEntries))
+			}
+			for i, msg := range w.messages {
+				entry := testEntries[i]
+				if string(msg.Key) != entry.Resource.InstanceID || !msg.Time.Equal(entry.Timestamp) {
+					t.Errorf("message[%d] key = %q, time = %v", i, msg.Key, msg.Time)
+				}
+				var got LogEntry
+				if err := json.Unmarshal(msg.Value, &got); err != nil || got.Message != entry.Message || got.Level != entry.Level {
+					t.Errorf("message[%d] value = %s, err = %v", i, msg.Value, err)
+				}
+			}
+
+			if err := sink.Close(); err != nil || !w.closed {
+				t.Errorf("Close() error = %v, closed = %v", err, w.closed)
+			}
+		})
+	}
+}
+
+func TestNewKafkaSink(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    SinkConfig
+		wantErr   bool
+		wantAddr  string
+		wantTopic string
+	}{
+		{name: "缺少topic", config: SinkConfig{Type: SinkKafka, Url: "kafka-1:9092"}, wantErr: true},
+		{name: "缺少broker", config: SinkConfig{Type: SinkKafka, Topic: "idrm-logs"}, wantErr: true},
+		{
+			name:     "broker列表去除空白",
+			config:   SinkConfig{Type: SinkKafka, Url: "kafka-1:9092, kafka-2:9092", Topic: "idrm-logs", Gzip: true},
+			wantAddr: "kafka-1:9092,kafka-2:9092", wantTopic: "idrm-logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := newKafkaSink(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("newKafkaSink() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newKafkaSink() error = %v", err)
+			}
+			defer sink.Close()
+
+			w := sink.writer.(*kafka.Writer)
+			if w.Addr.String() != tt.wantAddr || w.Topic != tt.wantTopic || w.Compression != kafka.Gzip {
+				t.Errorf("writer addr = %s, topic = %s, compression = %v", w.Addr, w.Topic, w.Compression)
+			}
+		})
+	}
+}
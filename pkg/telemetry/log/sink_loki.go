@@ -0,0 +1,53 @@
+package log
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// lokiPush Loki push API 请求体
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiStream 同一组标签的日志流
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"` // [纳秒时间戳, 日志行]
+}
+
+// encodeLoki 按 service/environment/level 分组为日志流
+// 标签只取低基数字段，trace_id 等高基数字段保留在日志行 JSON 中
+func encodeLoki(entries []LogEntry) ([]byte, error) {
+	var (
+		streams []lokiStream
+		index   = make(map[[3]string]int)
+	)
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		key := [3]string{entry.ServiceName, entry.Resource.Environment, entry.Level}
+		i, ok := index[key]
+		if !ok {
+			labels := map[string]string{
+				"service": entry.ServiceName,
+				"level":   entry.Level,
+			}
+			if entry.Resource.Environment != "" {
+				labels["env"] = entry.Resource.Environment
+			}
+			i = len(streams)
+			index[key] = i
+			streams = append(streams, lokiStream{Stream: labels})
+		}
+
+		ts := strconv.FormatInt(entry.Timestamp.UnixNano(), 10)
+		streams[i].Values = append(streams[i].Values, [2]string{ts, string(line)})
+	}
+
+	return json.Marshal(lokiPush{Streams: streams})
+}
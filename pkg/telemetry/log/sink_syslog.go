@@ -0,0 +1,217 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslog 常量
+const (
+	syslogFacility = 16               // local0
+	syslogSDID     = "idrm@32473"     // 结构化数据 ID（32473 为 RFC 5612 文档用企业号）
+	syslogNil      = "-"              // 空值
+	syslogTime     = time.RFC3339Nano // RFC 5424 时间戳
+	syslogMaxUDP   = 64*1024 - 8 - 20 // UDP 报文上限
+)
+
+// syslogSeverity 日志级别到 syslog 严重级别的映射
+var syslogSeverity = map[string]int{
+	"alert":  1,
+	"severe": 2,
+	"fatal":  2,
+	"error":  3,
+	"slow":   4,
+	"warn":   4,
+	"stat":   6,
+	"info":   6,
+	"debug":  7,
+}
+
+// syslogSink RFC 5424 syslog 投递目标
+// TCP 使用 RFC 6587 octet counting 分帧，UDP 每条日志一个报文；
+// 连接出错时关闭，下次发送重新建立
+type syslogSink struct {
+	network string
+	addr    string
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// newSyslogSink 创建 syslog 投递目标，Url 形如 tcp://host:514 或 udp://host:514
+func newSyslogSink(config SinkConfig) (*syslogSink, error) {
+	u, err := url.Parse(config.Url)
+	if err != nil {
+		return nil, fmt.Errorf("parse syslog url: %w", err)
+	}
+	if u.Scheme != "tcp" && u.Scheme != "udp" {
+		return nil, fmt.Errorf("unsupported syslog network: %s", u.Scheme)
+	}
+
+	return &syslogSink{
+		network: u.Scheme,
+		addr:    u.Host,
+		timeout: config.Timeout,
+	}, nil
+}
+
+// Send 逐条发送日志
+func (s *syslogSink) Send(ctx context.Context, entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetWriteDeadline(deadline)
+
+	for _, entry := range entries {
+		msg := formatSyslog(entry)
+		if s.network == "udp" && len(msg) > syslogMaxUDP {
+			msg = msg[:syslogMaxUDP]
+		}
+		if s.network == "tcp" {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		if _, err := conn.Write(msg); err != nil {
+			_ = conn.Close()
+			s.conn = nil
+			return fmt.Errorf("write syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// connect 获取连接，未连接时建立
+func (s *syslogSink) connect(ctx context.Context) (net.Conn, error) {
+	if s.conn != nil {
+		return s.conn, nil
+	}
+
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial syslog: %w", err)
+	}
+	s.conn = conn
+	return conn, nil
+}
+
+// Close 关闭连接
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// formatSyslog 格式化为 RFC 5424 消息：
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID param="value"...] MSG
+func formatSyslog(entry LogEntry) []byte {
+	severity, ok := syslogSeverity[entry.Level]
+	if !ok {
+		severity = syslogSeverity["info"]
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s %s ",
+		syslogFacility*8+severity,
+		entry.Timestamp.Format(syslogTime),
+		syslogHeader(entry.Resource.Hostname, 255),
+		syslogHeader(entry.ServiceName, 48),
+		syslogHeader(entry.Resource.InstanceID, 128),
+		syslogHeader(entry.Level, 32),
+	)
+
+	params := map[string]string{
+		"caller":          entry.Caller,
+		"trace_id":        entry.TraceID,
+		"span_id":         entry.SpanID,
+		"request_id":      entry.RequestID,
+		"service_version": entry.Resource.ServiceVersion,
+		"environment":     entry.Resource.Environment,
+	}
+	for k, v := range entry.Fields {
+		params[k] = fmt.Sprint(v)
+	}
+	writeStructuredData(&buf, params)
+
+	buf.WriteByte(' ')
+	buf.WriteString(entry.Message)
+	return buf.Bytes()
+}
+
+// writeStructuredData 写入结构化数据，参数按名称排序，无参数时写入 NILVALUE
+func writeStructuredData(buf *bytes.Buffer, params map[string]string) {
+	keys := make([]string, 0, len(params))
+	for k, v := range params {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		buf.WriteString(syslogNil)
+		return
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("[" + syslogSDID)
+	for _, k := range keys {
+		fmt.Fprintf(buf, ` %s="%s"`, syslogParamName(k), syslogEscaper.Replace(params[k]))
+	}
+	buf.WriteByte(']')
+}
+
+// syslogEscaper 参数值中 " \ ] 需要转义
+var syslogEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogHeader 头部字段只允许可见 ASCII 且有长度上限，空值写入 NILVALUE
+func syslogHeader(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return syslogNil
+	}
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	return s
+}
+
+// syslogParamName 参数名不允许 = ] " 和空格，最长 32 字符
+func syslogParamName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '=' || r == ']' || r == '"' || r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	return s
+}
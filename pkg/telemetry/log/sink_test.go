@@ -0,0 +1,217 @@
+package log
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testEntries = []LogEntry{
+	{
+		Timestamp:   time.Date(2024, 5, 6, 7, 8, 9, 123000000, time.UTC),
+		Level:       "error",
+		Message:     "查询失败",
+		ServiceName: "idrm-api",
+		RequestID:   "req-1",
+		Resource:    Resource{Environment: "prod", Hostname: "node-1", InstanceID: "node-1-42"},
+		Fields:      map[string]interface{}{"sql": `select "a"]`},
+	},
+	{
+		Timestamp:   time.Date(2024, 5, 6, 7, 8, 10, 0, time.UTC),
+		Level:       "info",
+		Message:     "服务启动",
+		ServiceName: "idrm-api",
+		Resource:    Resource{Environment: "prod"},
+	},
+}
+
+func TestHTTPSinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SinkConfig
+		path     string
+		auth     string
+		response string
+		check    func(t *testing.T, body []byte)
+	}{
+		{
+			name:   "通用HTTP",
+			config: SinkConfig{Type: SinkHTTP, Token: "t0ken"},
+			auth:   "Bearer t0ken",
+			check: func(t *testing.T, body []byte) {
+				var got struct{ Logs []LogEntry }
+				if err := json.Unmarshal(body, &got); err != nil || len(got.Logs) != 2 {
+					t.Fatalf("body = %s, err = %v", body, err)
+				}
+			},
+		},
+		{
+			name:   "Loki",
+			config: SinkConfig{Type: SinkLoki, Headers: map[string]string{"X-Scope-OrgID": "idrm"}},
+			check: func(t *testing.T, body []byte) {
+				var got lokiPush
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatalf("body = %s, err = %v", body, err)
+				}
+				if len(got.Streams) != 2 || got.Streams[0].Stream["level"] != "error" || got.Streams[0].Stream["env"] != "prod" {
+					t.Errorf("streams = %+v", got.Streams)
+				}
+				if got.Streams[0].Values[0][0] != "1714979289123000000" {
+					t.Errorf("timestamp = %s", got.Streams[0].Values[0][0])
+				}
+			},
+		},
+		{
+			name:     "Elasticsearch",
+			config:   SinkConfig{Type: SinkElasticsearch, Username: "elastic", Password: "secret"},
+			path:     "/_bulk",
+			auth:     "Basic ZWxhc3RpYzpzZWNyZXQ=",
+			response: `{"errors":false,"items":[]}`,
+			check: func(t *testing.T, body []byte) {
+				lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+				if len(lines) != 4 || lines[0] != `{"create":{"_index":"idrm-logs-2024.05.06"}}` {
+					t.Errorf("bulk body = %s", body)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.path != "" && r.URL.Path != tt.path {
+					t.Errorf("path = %s, want %s", r.URL.Path, tt.path)
+				}
+				if got := r.Header.Get("Authorization"); got != tt.auth {
+					t.Errorf("Authorization = %q, want %q", got, tt.auth)
+				}
+				for k, v := range tt.config.Headers {
+					if r.Header.Get(k) != v {
+						t.Errorf("header %s = %q, want %q", k, r.Header.Get(k), v)
+					}
+				}
+				if r.Header.Get("Content-Encoding") != "gzip" {
+					t.Errorf("Content-Encoding = %q, want gzip", r.Header.Get("Content-Encoding"))
+				}
+				zr, err := gzip.NewReader(r.Body)
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				body, _ = io.ReadAll(zr)
+				_, _ = io.WriteString(w, tt.response)
+			}))
+			defer srv.Close()
+
+			config := tt.config
+			config.Url = srv.URL
+			config.Gzip = true
+			sink, err := NewSink(config)
+			if err != nil {
+				t.Fatalf("NewSink() error = %v", err)
+			}
+			defer sink.Close()
+
+			if err := sink.Send(context.Background(), testEntries); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			tt.check(t, body)
+		})
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	for _, network := range []string{"tcp", "udp"} {
+		t.Run(network, func(t *testing.T) {
+			var (
+				addr     string
+				received = make(chan string, len(testEntries))
+			)
+			if network == "tcp" {
+				ln, err := net.Listen("tcp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer ln.Close()
+				addr = ln.Addr().String()
+				go readOctetCounted(ln, received)
+			} else {
+				pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer pc.Close()
+				addr = pc.LocalAddr().String()
+				go func() {
+					buf := make([]byte, 64<<10)
+					for {
+						n, _, err := pc.ReadFrom(buf)
+						if err != nil {
+							return
+						}
+						received <- string(buf[:n])
+					}
+				}()
+			}
+
+			sink, err := NewSink(SinkConfig{Type: SinkSyslog, Url: network + "://" + addr})
+			if err != nil {
+				t.Fatalf("NewSink() error = %v", err)
+			}
+			defer sink.Close()
+			if err := sink.Send(context.Background(), testEntries); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			want := []string{
+				`<131>1 2024-05-06T07:08:09.123Z node-1 idrm-api node-1-42 error [idrm@32473 environment="prod" request_id="req-1" sql="select \"a\"\]"] 查询失败`,
+				`<134>1 2024-05-06T07:08:10Z - idrm-api - info [idrm@32473 environment="prod"] 服务启动`,
+			}
+			for _, w := range want {
+				select {
+				case got := <-received:
+					if got != w {
+						t.Errorf("message = %s\nwant      %s", got, w)
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatal("timeout waiting for syslog message")
+				}
+			}
+		})
+	}
+}
+
+// readOctetCounted 按 RFC 6587 octet counting 读取 TCP syslog 消息
+func readOctetCounted(ln net.Listener, out chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		size, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		out <- string(msg)
+	}
+}
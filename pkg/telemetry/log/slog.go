@@ -0,0 +1,132 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// slogMaxDepth 查找 slog 调用方时的最大栈深度
+const slogMaxDepth = 16
+
+// slogHandler 将 log/slog 日志转发到 logx 的 slog.Handler
+// 输出经过同一 Writer 链（级别过滤、脱敏、远程投递），并携带上下文字段
+//
+// 级别映射：Debug → debug，Info/Warn → info，Error 及以上 → error
+type slogHandler struct {
+	ctx    context.Context // Logger.Slog 绑定的上下文，记录未指定上下文时使用
+	fields []logx.LogField
+	group  string // 分组前缀，如 "db."
+}
+
+// NewSlogHandler 创建转发到 logx 的 slog.Handler
+// log.Init 会将其设为 slog 默认 Handler，使用 slog 或标准库 log 的第三方库日志格式一致
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{}
+}
+
+// Enabled 按当前最详细的级别快速过滤，包/路由级别由 levelWriter 再次过滤
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return slogLevel(level) >= levels.snapshot.Load().lowest
+}
+
+// Handle 输出日志，caller 指向调用 slog 的业务代码
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.ctx != nil && (ctx == nil || ctx == context.Background()) {
+		ctx = h.ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	fields := make([]logx.LogField, 0, len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.fields...)
+	r.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, h.group, attr)
+		return true
+	})
+
+	logger := logx.WithContext(ctx).WithCallerSkip(slogCallerSkip(r.PC)).WithFields(fields...)
+	switch slogLevel(r.Level) {
+	case logx.DebugLevel:
+		logger.Debugw(r.Message)
+	case logx.InfoLevel:
+		logger.Infow(r.Message)
+	default:
+		logger.Errorw(r.Message)
+	}
+	return nil
+}
+
+// WithAttrs 返回附加字段的 Handler
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]logx.LogField, 0, len(h.fields)+len(attrs))
+	fields = append(fields, h.fields...)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, h.group, attr)
+	}
+	return &slogHandler{ctx: h.ctx, fields: fields, group: h.group}
+}
+
+// WithGroup 返回字段名带分组前缀的 Handler
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{ctx: h.ctx, fields: h.fields, group: h.group + name + "."}
+}
+
+// slogLevel slog 级别映射为 logx 级别
+func slogLevel(level slog.Level) uint32 {
+	switch {
+	case level < slog.LevelInfo:
+		return logx.DebugLevel
+	case level < slog.LevelError:
+		return logx.InfoLevel
+	default:
+		return logx.ErrorLevel
+	}
+}
+
+// appendAttr 转换 slog 属性，分组属性展开为带前缀的字段
+func appendAttr(fields []logx.LogField, prefix string, attr slog.Attr) []logx.LogField {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		group := attr.Value.Group()
+		if attr.Key != "" {
+			prefix = prefix + attr.Key + "."
+		}
+		for _, a := range group {
+			fields = appendAttr(fields, prefix, a)
+		}
+		return fields
+	}
+	return append(fields, logx.Field(prefix+attr.Key, attr.Value.Any()))
+}
+
+// slogCallerSkip 计算从 Handle 到 slog 调用方的栈帧数，使 logx 的 caller 字段指向业务代码
+// 找不到时返回 0（caller 为本文件）
+func slogCallerSkip(pc uintptr) int {
+	if pc == 0 {
+		return 0
+	}
+	var pcs [slogMaxDepth]uintptr
+	// 跳过 runtime.Callers 和本函数，pcs[0] 位于 Handle
+	n := runtime.Callers(2, pcs[:])
+	for i := 0; i < n; i++ {
+		if pcs[i] == pc {
+			return i
+		}
+	}
+	return 0
+}
+
+// installSlog 将 logx 设为 slog 默认输出
+func installSlog() {
+	slog.SetDefault(slog.New(NewSlogHandler()))
+}
@@ -0,0 +1,47 @@
+package mask
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Attributes 按键名规则脱敏 Span 属性，未命中规则时返回原切片及 false
+func Attributes(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		rule := KeyRule(string(kv.Key))
+		if rule == "" {
+			continue
+		}
+
+		masked, ok := maskAttribute(rule, kv)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make([]attribute.KeyValue, len(attrs))
+			copy(out, attrs)
+		}
+		out[i] = masked
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+// maskAttribute 脱敏单个属性，布尔值不处理
+func maskAttribute(rule string, kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	switch kv.Value.Type() {
+	case attribute.BOOL:
+		return kv, false
+	case attribute.STRINGSLICE:
+		values := kv.Value.AsStringSlice()
+		masked := make([]string, len(values))
+		for i, v := range values {
+			masked[i] = String(rule, v)
+		}
+		return kv.Key.StringSlice(masked), true
+	default:
+		return kv.Key.String(String(rule, kv.Value.Emit())), true
+	}
+}
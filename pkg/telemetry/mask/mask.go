@@ -0,0 +1,321 @@
+package mask
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// 脱敏规则
+const (
+	RuleMobile = "mobile" // 手机号：保留前 3 后 4 位，138****8000
+	RuleIDCard = "idcard" // 身份证号：保留前 3 后 4 位，110***********1234
+	RuleSecret = "secret" // 密码/令牌：固定替换为 ******，不暴露长度
+)
+
+// tagName 结构体字段脱敏标签，如 `mask:"mobile"`
+const tagName = "mask"
+
+// secretMask 密文掩码
+const secretMask = "******"
+
+// maxDepth 最大递归深度，避免循环引用
+const maxDepth = 16
+
+// defaultKeys 默认键名规则（键名规范化后匹配，见 normalizeKey）
+var defaultKeys = map[string]string{
+	"password":      RuleSecret,
+	"passwd":        RuleSecret,
+	"pwd":           RuleSecret,
+	"secret":        RuleSecret,
+	"token":         RuleSecret,
+	"accesstoken":   RuleSecret,
+	"refreshtoken":  RuleSecret,
+	"accesssecret":  RuleSecret,
+	"authorization": RuleSecret,
+	"apikey":        RuleSecret,
+	"privatekey":    RuleSecret,
+	"cookie":        RuleSecret,
+	"mobile":        RuleMobile,
+	"phone":         RuleMobile,
+	"phonenumber":   RuleMobile,
+	"idcard":        RuleIDCard,
+	"idcardno":      RuleIDCard,
+	"idno":          RuleIDCard,
+}
+
+// keyRules 当前键名规则
+var keyRules atomic.Pointer[map[string]string]
+
+func init() {
+	Configure(Config{})
+}
+
+// Config 脱敏配置
+type Config struct {
+	// Keys 追加或覆盖键名规则，如 {"bank_card": "idcard"}；规则为空表示取消默认规则
+	Keys map[string]string `json:",optional"`
+}
+
+// Configure 设置键名规则（在默认规则基础上追加或覆盖）
+func Configure(c Config) {
+	rules := make(map[string]string, len(defaultKeys)+len(c.Keys))
+	for k, v := range defaultKeys {
+		rules[k] = v
+	}
+	for k, v := range c.Keys {
+		key := normalizeKey(k)
+		if v == "" {
+			delete(rules, key)
+			continue
+		}
+		rules[key] = v
+	}
+	keyRules.Store(&rules)
+}
+
+// KeyRule 返回键名对应的脱敏规则，未命中返回空
+// 键名忽略大小写及 _ - . 分隔符；长度不少于 5 的规则同时按后缀匹配（如 user_mobile、db.password）
+func KeyRule(key string) string {
+	key = normalizeKey(key)
+	rules := *keyRules.Load()
+	if rule, ok := rules[key]; ok {
+		return rule
+	}
+	for k, rule := range rules {
+		if len(k) >= 5 && strings.HasSuffix(key, k) {
+			return rule
+		}
+	}
+	return ""
+}
+
+// normalizeKey 转小写并去除分隔符
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', ' ':
+			return -1
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, key)
+}
+
+// String 按规则脱敏字符串，未知规则按 secret 处理
+func String(rule, s string) string {
+	if s == "" {
+		return s
+	}
+	switch rule {
+	case RuleMobile, RuleIDCard:
+		return keepEnds(s, 3, 4)
+	default:
+		return secretMask
+	}
+}
+
+// keepEnds 保留首尾字符，中间替换为 *；长度不足时全部替换
+func keepEnds(s string, head, tail int) string {
+	runes := []rune(s)
+	if len(runes) <= head+tail {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:head]) + strings.Repeat("*", len(runes)-head-tail) + string(runes[len(runes)-tail:])
+}
+
+// Field 按键名规则脱敏单个字段值（日志字段、Span 属性等）
+func Field(key string, value any) any {
+	if rule := KeyRule(key); rule != "" {
+		return maskScalar(rule, value)
+	}
+	return Value(value)
+}
+
+// Value 返回脱敏后的值：结构体按 mask 标签及键名规则处理并转换为 map（键为 json 名称），
+// map 按键名规则处理，切片逐个处理，其余类型原样返回
+func Value(v any) any {
+	switch v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64, error:
+		return v
+	}
+	return maskValue(reflect.ValueOf(v), 0)
+}
+
+// JSON 脱敏 JSON 文本，无法解析时返回 false
+func JSON(data []byte) ([]byte, bool) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, false
+	}
+	masked, err := json.Marshal(Value(v))
+	if err != nil {
+		return nil, false
+	}
+	return masked, true
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// maskValue 递归脱敏
+func maskValue(rv reflect.Value, depth int) any {
+	if !rv.IsValid() {
+		return nil
+	}
+	if depth > maxDepth {
+		return rv.Interface()
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Kind() == reflect.Pointer && customMarshal(rv.Type()) {
+			return rv.Interface()
+		}
+		return maskValue(rv.Elem(), depth+1)
+	case reflect.Struct:
+		if customMarshal(rv.Type()) {
+			return rv.Interface()
+		}
+		out := make(map[string]any, rv.NumField())
+		maskStruct(rv, out, depth)
+		return out
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return rv.Interface()
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if rule := KeyRule(key); rule != "" {
+				out[key] = maskScalar(rule, iter.Value().Interface())
+				continue
+			}
+			out[key] = maskValue(iter.Value(), depth+1)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Interface()
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = maskValue(rv.Index(i), depth+1)
+		}
+		return out
+	default:
+		if !rv.CanInterface() {
+			return nil
+		}
+		return rv.Interface()
+	}
+}
+
+// maskStruct 按 json 名称展开结构体字段，匿名嵌入结构体合并到同一层
+func maskStruct(rv reflect.Value, out map[string]any, depth int) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		// 与 encoding/json 一致：未导出的匿名结构体字段仍会提升其导出字段
+		if !field.IsExported() && !(field.Anonymous && indirect(field.Type).Kind() == reflect.Struct) {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		fv := rv.Field(i)
+
+		if field.Anonymous && name == "" {
+			embedded := fv
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !customMarshal(embedded.Type()) {
+				maskStruct(embedded, out, depth+1)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		rule := field.Tag.Get(tagName)
+		if rule == "" {
+			rule = KeyRule(name)
+		}
+		if rule != "" && rule != "-" {
+			out[name] = maskScalar(rule, fv.Interface())
+			continue
+		}
+		out[name] = maskValue(fv, depth+1)
+	}
+}
+
+// indirect 返回指针指向的类型
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// jsonName 解析 json 标签
+func jsonName(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(opts, "omitempty"), false
+}
+
+// customMarshal 类型是否自定义了 JSON/文本序列化（如 time.Time），此类值不展开
+func customMarshal(t reflect.Type) bool {
+	return t.Implements(jsonMarshaler) || t.Implements(textMarshaler) ||
+		reflect.PointerTo(t).Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(textMarshaler)
+}
+
+// maskScalar 按规则脱敏任意值：字符串及基础类型按文本脱敏，nil 和空值原样返回
+func maskScalar(rule string, v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return String(rule, val)
+	case *string:
+		if val == nil {
+			return nil
+		}
+		return String(rule, *val)
+	case fmt.Stringer:
+		return String(rule, val.String())
+	default:
+		return String(rule, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,170 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		input string
+		want  string
+	}{
+		{"手机号", RuleMobile, "13800138000", "138****8000"},
+		{"身份证号", RuleIDCard, "110101199001011234", "110***********1234"},
+		{"密码固定掩码", RuleSecret, "p@ss", "******"},
+		{"过短全部掩码", RuleMobile, "1380", "****"},
+		{"空字符串", RuleSecret, "", ""},
+		{"未知规则按密文", "unknown", "abc", "******"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.rule, tt.input); got != tt.want {
+				t.Errorf("String(%q, %q) = %q, want %q", tt.rule, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyRule(t *testing.T) {
+	defer Configure(Config{})
+	Configure(Config{Keys: map[string]string{"bank_card": RuleIDCard, "cookie": ""}})
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"password", RuleSecret},
+		{"Access-Token", RuleSecret},
+		{"user_mobile", RuleMobile},
+		{"db.password", RuleSecret},
+		{"id_card", RuleIDCard},
+		{"bankCard", RuleIDCard},
+		{"cookie", ""},
+		{"name", ""},
+		{"xpwd", ""}, // 短规则不按后缀匹配
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := KeyRule(tt.key); got != tt.want {
+				t.Errorf("KeyRule(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+type address struct {
+	City  string `json:"city"`
+	Phone string `json:"phone"`
+}
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type user struct {
+	base
+	Name      string    `json:"name"`
+	Mobile    string    `json:"contact" mask:"mobile"`
+	IDCard    string    `json:"id_card"`
+	Password  string    `json:"password"`
+	Note      string    `json:"note,omitempty"`
+	Hidden    string    `json:"-"`
+	Raw       string    `json:"raw" mask:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	Address   *address  `json:"address"`
+	Tags      []address `json:"tags"`
+}
+
+func TestValue(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{"基础类型原样返回", 42, 42},
+		{"nil", nil, nil},
+		{
+			"结构体标签及键名规则",
+			&user{
+				base:      base{ID: 1},
+				Name:      "张三",
+				Mobile:    "13800138000",
+				IDCard:    "110101199001011234",
+				Password:  "secret",
+				Hidden:    "x",
+				Raw:       "token-like",
+				CreatedAt: created,
+				Address:   &address{City: "北京", Phone: "13912345678"},
+				Tags:      []address{{City: "上海", Phone: "13700001111"}},
+			},
+			map[string]any{
+				"id":         int64(1),
+				"name":       "张三",
+				"contact":    "138****8000",
+				"id_card":    "110***********1234",
+				"password":   "******",
+				"raw":        "token-like",
+				"created_at": created,
+				"address":    map[string]any{"city": "北京", "phone": "139****5678"},
+				"tags":       []any{map[string]any{"city": "上海", "phone": "137****1111"}},
+			},
+		},
+		{
+			"map 按键名规则",
+			map[string]any{"token": "abc", "nested": map[string]string{"mobile": "13800138000"}},
+			map[string]any{"token": "******", "nested": map[string]any{"mobile": "138****8000"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	got, ok := JSON([]byte(`{"mobile":"13800138000","items":[{"password":"x"}]}`))
+	if !ok {
+		t.Fatal("JSON() ok = false")
+	}
+	if want := `{"items":[{"password":"******"}],"mobile":"138****8000"}`; string(got) != want {
+		t.Errorf("JSON() = %s, want %s", got, want)
+	}
+	if _, ok := JSON([]byte("not json")); ok {
+		t.Error("JSON(invalid) ok = true, want false")
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.method", "GET"),
+		attribute.String("user.mobile", "13800138000"),
+		attribute.Int("auth.token", 123),
+	}
+	got, changed := Attributes(attrs)
+	if !changed {
+		t.Fatal("Attributes() changed = false")
+	}
+	if got[0] != attrs[0] || got[1].Value.AsString() != "138****8000" || got[2].Value.AsString() != "******" {
+		t.Errorf("Attributes() = %v", got)
+	}
+	if attrs[1].Value.AsString() != "13800138000" {
+		t.Error("Attributes() modified input slice")
+	}
+
+	if _, changed := Attributes(attrs[:1]); changed {
+		t.Error("Attributes(no sensitive) changed = true")
+	}
+}
@@ -0,0 +1,23 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+)
+
+// ShutdownTimeout 关闭时排空审计积压、远程日志队列和缓存 Span 的默认时间上限
+const ShutdownTimeout = 10 * time.Second
+
+// Serve 运行服务直到关闭完成，再在 timeout 内关闭 Telemetry 系统（默认实例）
+// start 需阻塞到服务停止接收请求且进行中的请求全部结束（rest.Server.Start 在 go-zero 关闭监听执行完后返回），
+// 请求中记录的审计日志因此在关闭预写队列之前写入，随 Close 一起投递；
+// 不要在 proc 关闭监听中调用 Close：监听与 HTTP 服务的关闭并发执行，进行中请求的审计日志会因队列已关闭被丢弃
+//
+//	telemetry.Serve(server.Start, telemetry.ShutdownTimeout)
+func Serve(start func(), timeout time.Duration) {
+	start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	Close(ctx)
+}
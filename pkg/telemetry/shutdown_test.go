@@ -0,0 +1,156 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/telemetry/trace"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/proc"
+	"github.com/zeromicro/go-zero/rest"
+)
+
+func TestServeDrainsAuditAfterRequests(t *testing.T) {
+	// 审计服务记录收到的事件
+	var (
+		mu      sync.Mutex
+		actions []string
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			AuditLogs []audit.AuditLog `json:"audit_logs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		for _, l := range body.AuditLogs {
+			actions = append(actions, l.Action)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer collector.Close()
+
+	p, err := New(Config{
+		ServiceName: "idrm-test",
+		Audit: AuditConfig{
+			Enabled: true, Url: collector.URL, Buffer: 100, FlushInterval: 1,
+			WALDir: t.TempDir(), MaxBackoff: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	prevTrace, prevAudit := trace.Default(), audit.Default()
+	SetDefault(p)
+	defer func() {
+		std = nil
+		trace.SetDefault(prevTrace)
+		audit.SetDefault(prevAudit)
+	}()
+
+	// 处理中的请求在关闭开始后才记录审计日志
+	entered, release := make(chan struct{}), make(chan struct{})
+	var c rest.RestConf
+	if err := conf.FillDefault(&c); err != nil {
+		t.Fatalf("FillDefault() error = %v", err)
+	}
+	c.Name, c.Log.Mode, c.Host, c.Port = "idrm-test", "console", "127.0.0.1", freePort(t)
+	server := rest.MustNewServer(c)
+	server.AddRoute(rest.Route{Method: http.MethodPost, Path: "/slow", Handler: func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		audit.Log(r.Context(), audit.AuditLog{Action: audit.ActionUpdate, Resource: audit.ResourceCategory})
+		w.WriteHeader(http.StatusOK)
+	}})
+
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		Serve(server.Start, 5*time.Second)
+	}()
+
+	addr := fmt.Sprintf("http://%s:%d/slow", c.Host, c.Port)
+	respond := make(chan error, 1)
+	go func() {
+		resp, err := postWhenReady(addr)
+		if err == nil {
+			resp.Body.Close()
+		}
+		respond <- err
+	}()
+	<-entered
+
+	// 开始关闭：服务停止接收新连接后再放行请求
+	go proc.Shutdown()
+	waitClosed(t, c.Host, c.Port)
+	close(release)
+
+	if err := <-respond; err != nil {
+		t.Fatalf("in-flight request error = %v", err)
+	}
+	select {
+	case <-served:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve() did not return after shutdown")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(actions) != 1 || actions[0] != audit.ActionUpdate {
+		t.Errorf("delivered actions = %v, want [%s]", actions, audit.ActionUpdate)
+	}
+	if stats := p.Audit().Stats(); stats.Dropped != 0 || stats.Backlog != 0 {
+		t.Errorf("audit stats = %+v, want nothing dropped or pending", stats)
+	}
+}
+
+// freePort 返回一个空闲端口
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// postWhenReady 服务启动后发送请求
+func postWhenReady(url string) (*http.Response, error) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Post(url, "application/json", nil)
+		if err == nil || time.Now().After(deadline) {
+			return resp, err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitClosed 等待服务停止接收新连接
+func waitClosed(t *testing.T, host string, port int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", host, port), 100*time.Millisecond)
+		if err != nil {
+			return
+		}
+		conn.Close()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("server still accepting connections after shutdown")
+}
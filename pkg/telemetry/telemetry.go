@@ -2,65 +2,288 @@ package telemetry
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"idrm/pkg/telemetry/audit"
 	"idrm/pkg/telemetry/log"
+	"idrm/pkg/telemetry/mask"
 	"idrm/pkg/telemetry/trace"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 )
 
+// std 默认实例，由 Init 设置；包级函数（GetHealth、Close 等）使用
+var std *Provider
+
+// levelAuditOnce 多次 Init 时只注册一次级别变更审计（回调写入当时的默认审计实例）
+var levelAuditOnce sync.Once
+
+// Provider 遥测实例，持有链路追踪、日志系统、审计日志和 Meter
+// 由 Init 返回并存入 ServiceContext，通过构造参数传给中间件和业务代码；
+// 日志系统（远程 Writer、级别监听、折叠）由 Init 创建，输出基于进程级的 go-zero logx，同一进程只有一个生效的日志配置
+// 方法对 nil 安全：nil 实例使用各包的默认实例，非 nil 实例中为 nil 的组件表示未启用
+type Provider struct {
+	serviceName string
+	tracer      *trace.Provider
+	logs        *log.System
+	audit       *audit.AuditLogger
+	meter       metric.Meter
+}
+
 // Init 初始化 Telemetry 系统（一站式初始化）
-func Init(config Config) error {
+// 设置进程级配置（脱敏规则、logx、Propagator），创建实例并设为默认实例，
+// 包级函数（trace.Start、audit.Log 等）使用该实例
+func Init(config Config) (*Provider, error) {
+	// 0. 脱敏规则（日志、审计、链路追踪写出前统一脱敏）
+	mask.Configure(mask.Config{Keys: config.Mask.Keys})
+
 	// 1. 初始化日志系统
+	logs := log.Init(newLogConfig(config), config.ServiceName)
+	logx.Infof("Telemetry 初始化: %s v%s (%s)",
+		config.ServiceName, config.ServiceVersion, config.Environment)
+
+	// 2. 进程级链路传播配置（Propagator、Baggage 策略）
+	if err := trace.Configure(newTraceConfig(config)); err != nil {
+		logx.Errorf("链路追踪初始化失败: %v", err)
+		return nil, err
+	}
+
+	// 3. 创建实例并设为默认实例
+	p, err := New(config)
+	if err != nil {
+		return nil, err
+	}
+	p.logs = logs
+	SetDefault(p)
+
+	// 4. 日志级别变更写入审计日志（步骤 1 加载级别文件产生的变更在注册时补发，此时审计日志已创建）
+	levelAuditOnce.Do(func() { log.OnLevelChange(auditLevelChange) })
+
+	logx.Info("Telemetry 系统初始化完成")
+	return p, nil
+}
+
+// New 创建遥测实例（链路追踪、审计日志、Meter），不修改进程级配置和默认实例
+// 同一进程可创建多个实例（如测试或多租户隔离导出），日志仍由 Init 统一配置
+// Meter 来自 otel 全局 MeterProvider（未设置时为 noop）
+func New(config Config) (*Provider, error) {
+	// 链路追踪（不等待 Collector 连接，仅配置错误时返回）
+	tracer, err := trace.New(newTraceConfig(config), config.ServiceName, config.ServiceVersion, config.Environment)
+	if err != nil {
+		logx.Errorf("链路追踪初始化失败: %v", err)
+		return nil, err
+	}
+
+	// 审计日志
+	meter := otel.GetMeterProvider().Meter(config.ServiceName)
+	auditLogger := audit.New(audit.AuditConfig{
+		Enabled:       config.Audit.Enabled,
+		Url:           config.Audit.Url,
+		Buffer:        config.Audit.Buffer,
+		FlushInterval: time.Duration(config.Audit.FlushInterval) * time.Second,
+		WALDir:        config.Audit.WALDir,
+		WALSync:       config.Audit.WALSync,
+		MaxBackoff:    time.Duration(config.Audit.MaxBackoff) * time.Second,
+		HMACKey:       config.Audit.HMACKey,
+		File:          config.Audit.File,
+		Meter:         meter,
+	}, config.ServiceName)
+
+	return &Provider{
+		serviceName: config.ServiceName,
+		tracer:      tracer,
+		audit:       auditLogger,
+		meter:       meter,
+	}, nil
+}
+
+// NewProvider 使用已有组件创建实例（用于测试，如注入 tracetest 记录器的链路追踪实例）
+// 为 nil 的组件表示未启用（Meter 为 nil 时使用 otel 全局 MeterProvider）；实例不持有日志系统
+func NewProvider(serviceName string, tracer *trace.Provider, auditLogger *audit.AuditLogger, meter metric.Meter) *Provider {
+	return &Provider{serviceName: serviceName, tracer: tracer, audit: auditLogger, meter: meter}
+}
+
+// Default 返回默认实例，未调用 Init 时为 nil（方法仍可调用，使用各包的默认实例）
+func Default() *Provider {
+	return std
+}
+
+// SetDefault 设置默认实例，同时设为 trace、audit 包的默认实例
+func SetDefault(p *Provider) {
+	std = p
+	trace.SetDefault(p.Tracer())
+	audit.SetDefault(p.Audit())
+}
+
+// Tracer 返回链路追踪实例，未启用时为 nil（方法对 nil 安全，Start 返回 noop Span）
+func (p *Provider) Tracer() *trace.Provider {
+	if p == nil {
+		return trace.Default()
+	}
+	return p.tracer
+}
+
+// Logs 返回日志系统实例，NewProvider 创建的实例为 nil（方法对 nil 安全）
+func (p *Provider) Logs() *log.System {
+	if p == nil {
+		return log.Default()
+	}
+	return p.logs
+}
+
+// Audit 返回审计日志记录器，未启用时为 nil（方法对 nil 安全）
+func (p *Provider) Audit() *audit.AuditLogger {
+	if p == nil {
+		return audit.Default()
+	}
+	return p.audit
+}
+
+// Meter 返回 Meter
+func (p *Provider) Meter() metric.Meter {
+	if p == nil || p.meter == nil {
+		return otel.GetMeterProvider().Meter("idrm/pkg/telemetry")
+	}
+	return p.meter
+}
+
+// Logger 返回上下文日志记录器（自动携带 trace、请求 ID 等字段）
+func (p *Provider) Logger(ctx context.Context) *log.Logger {
+	return p.Logs().FromContext(ctx)
+}
+
+// Close 关闭实例：投递积压的审计日志，导出剩余 Span，最后关闭持有的日志系统（远程日志队列、折叠汇总）
+// ctx 到期时未投递的审计日志保留在预写文件中，下次启动时继续投递
+func (p *Provider) Close(ctx context.Context) {
+	if p == nil {
+		return
+	}
+	_ = p.audit.Close(ctx)
+	_ = p.tracer.Shutdown(ctx)
+	p.logs.Close(ctx)
+}
+
+// Close 关闭 Telemetry 系统：关闭默认实例（包括日志系统）
+func Close(ctx context.Context) {
+	logx.Info("正在关闭 Telemetry 系统...")
+
+	if std == nil {
+		_ = audit.Close(ctx)
+		_ = trace.Close(ctx)
+		log.Close(ctx)
+		return
+	}
+	std.Close(ctx)
+	if std.logs == nil {
+		// 默认实例由 SetDefault 设置、不持有日志系统时，关闭 Init 创建的日志系统
+		log.Close(ctx)
+	}
+}
+
+// newLogConfig 转换日志配置
+func newLogConfig(config Config) log.LogConfig {
 	logConfig := log.LogConfig{
+		ServiceVersion: config.ServiceVersion,
+		Environment:    config.Environment,
+
 		Level:         config.Log.Level,
 		Mode:          config.Log.Mode,
 		Path:          config.Log.Path,
 		KeepDays:      config.Log.KeepDays,
+		LevelFile:     config.Log.LevelFile,
 		RemoteEnabled: config.Log.RemoteEnabled,
+		RemoteSink:    config.Log.RemoteSink,
 		RemoteUrl:     config.Log.RemoteUrl,
 		RemoteBatch:   config.Log.RemoteBatch,
 		RemoteTimeout: config.Log.RemoteTimeout,
+
+		RemoteGzip:     config.Log.RemoteGzip,
+		RemoteUsername: config.Log.RemoteUsername,
+		RemotePassword: config.Log.RemotePassword,
+		RemoteToken:    config.Log.RemoteToken,
+		RemoteHeaders:  config.Log.RemoteHeaders,
+		RemoteIndex:    config.Log.RemoteIndex,
+		RemoteTopic:    config.Log.RemoteTopic,
+
+		RemoteQueueSize:  config.Log.RemoteQueueSize,
+		RemoteOverflow:   config.Log.RemoteOverflow,
+		RemoteMaxRetries: config.Log.RemoteMaxRetries,
+		RemoteSpoolDir:   config.Log.RemoteSpoolDir,
+		RemoteSpoolMaxMB: config.Log.RemoteSpoolMaxMB,
+		RemoteSpoolFiles: config.Log.RemoteSpoolFiles,
+
+		DedupEnabled: config.Log.DedupEnabled,
 	}
-	log.Init(logConfig, config.ServiceName)
-	logx.Infof("Telemetry 初始化: %s v%s (%s)",
-		config.ServiceName, config.ServiceVersion, config.Environment)
+	for _, rule := range config.Log.DedupRules {
+		logConfig.DedupRules = append(logConfig.DedupRules, log.DedupRule{
+			Level:  rule.Level,
+			Window: time.Duration(rule.Window) * time.Second,
+			Burst:  rule.Burst,
+		})
+	}
+	return logConfig
+}
 
-	// 2. 初始化链路追踪
+// newTraceConfig 转换链路追踪配置
+func newTraceConfig(config Config) trace.TraceConfig {
 	traceConfig := trace.TraceConfig{
 		Enabled:  config.Trace.Enabled,
 		Endpoint: config.Trace.Endpoint,
 		Sampler:  config.Trace.Sampler,
 		Batcher:  config.Trace.Batcher,
-	}
-	if err := trace.Init(traceConfig, config.ServiceName, config.ServiceVersion, config.Environment); err != nil {
-		logx.Errorf("链路追踪初始化失败: %v", err)
-		return err
-	}
 
-	// 3. 初始化审计日志
-	auditConfig := audit.AuditConfig{
-		Enabled: config.Audit.Enabled,
-		Url:     config.Audit.Url,
-		Buffer:  config.Audit.Buffer,
-	}
-	audit.Init(auditConfig, config.ServiceName)
+		Propagators: config.Trace.Propagators,
+		Baggage: trace.BaggageConfig{
+			Allow:   config.Trace.BaggageAllow,
+			Trusted: config.Trace.BaggageTrusted,
+			Fields:  config.Trace.BaggageFields,
+		},
+		TrustedProxies: config.Trace.TrustedProxies,
 
-	logx.Info("Telemetry 系统初始化完成")
-	return nil
-}
+		Headers:     config.Trace.Headers,
+		Compression: config.Trace.Compression,
 
-// Close 关闭 Telemetry 系统
-func Close(ctx context.Context) {
-	logx.Info("正在关闭 Telemetry 系统...")
+		TLSEnabled:    config.Trace.TLSEnabled,
+		TLSSkipVerify: config.Trace.TLSSkipVerify,
+		CAFile:        config.Trace.CAFile,
+		CertFile:      config.Trace.CertFile,
+		KeyFile:       config.Trace.KeyFile,
 
-	// 关闭审计日志
-	audit.Close()
+		FilePath: config.Trace.FilePath,
 
-	// 关闭链路追踪
-	trace.Close(ctx)
+		DownPolicy: config.Trace.DownPolicy,
+		BufferSize: config.Trace.BufferSize,
+
+		TailSampling:  config.Trace.TailSampling,
+		TailLatency:   time.Duration(config.Trace.TailLatency) * time.Millisecond,
+		TailMaxTraces: config.Trace.TailMaxTraces,
+	}
+	for _, rule := range config.Trace.SamplingRules {
+		traceConfig.SamplingRules = append(traceConfig.SamplingRules, trace.SamplingRule{
+			Route: rule.Route,
+			Ratio: rule.Ratio,
+		})
+	}
+	return traceConfig
+}
 
-	// 关闭日志系统（最后关闭）
-	log.Close()
+// auditLevelChange 记录日志级别变更审计
+func auditLevelChange(ctx context.Context, change log.LevelChange) {
+	audit.Log(ctx, audit.AuditLog{
+		Action:   audit.ActionUpdate,
+		Resource: audit.ResourceLogLevel,
+		Username: change.Operator,
+		Before:   change.Before,
+		After:    change.After,
+		Success:  true,
+		Extra: map[string]interface{}{
+			"scope":  change.Scope,
+			"name":   change.Name,
+			"ttl":    change.TTL.String(),
+			"source": change.Source,
+		},
+	})
 }
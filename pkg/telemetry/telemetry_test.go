@@ -0,0 +1,81 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"idrm/pkg/telemetry/audit"
+	"idrm/pkg/telemetry/trace"
+	"idrm/pkg/telemetry/trace/tracetest"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	sdktracetest "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestProviderIsolation(t *testing.T) {
+	// 默认实例使用记录器
+	rec := tracetest.New(t)
+
+	// 独立实例使用自己的导出器
+	exporter := sdktracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+	p := NewProvider("idrm-test", trace.NewWithTracerProvider(tp), nil, nil)
+
+	_, span := p.Tracer().Start(context.Background(), "instance")
+	span.End()
+	_, span = trace.Start(context.Background(), "default")
+	span.End()
+
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Name != "instance" {
+		t.Errorf("instance spans = %v, want [instance]", spans)
+	}
+	rec.AssertCount(t, 1)
+	rec.AssertNone(t, "instance")
+}
+
+func TestProviderDefaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         *Provider
+		wantSpans int
+	}{
+		{"nil 实例使用默认实例", nil, 1},
+		{"未启用组件不使用默认实例", NewProvider("idrm-test", nil, nil, nil), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := tracetest.New(t)
+
+			// 方法均可安全调用
+			_, span := tt.p.Tracer().Start(context.Background(), "op")
+			span.End()
+			rec.AssertCount(t, tt.wantSpans)
+			tt.p.Logger(context.Background()).Infow("op")
+
+			tt.p.Audit().Log(context.Background(), audit.AuditLog{Action: audit.ActionQuery})
+			if tt.p.Meter() == nil {
+				t.Error("Meter() = nil")
+			}
+			if h := tt.p.Health(); h.Status != HealthUp {
+				t.Errorf("Health().Status = %s, want %s", h.Status, HealthUp)
+			}
+			tt.p.Close(context.Background())
+		})
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	p, err := New(Config{ServiceName: "idrm-test"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if p.Audit() != nil {
+		t.Error("Audit() != nil, want disabled")
+	}
+	if h := p.Health(); h.Trace.State != trace.StateDisabled {
+		t.Errorf("trace state = %s, want %s", h.Trace.State, trace.StateDisabled)
+	}
+	p.Close(context.Background())
+}
@@ -0,0 +1,211 @@
+package trace
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Baggage 成员键名
+const (
+	BaggageTenantID   = "tenant.id"  // 租户 ID
+	BaggageUserID     = "user.id"    // 用户 ID
+	BaggageClientApp  = "client.app" // 调用方应用（如 web、ios、idrm-gateway）
+	BaggageExperiment = "exp."       // 实验标记前缀，如 exp.new_search=b
+)
+
+// 默认策略
+var (
+	// defaultBaggageAllow 非受信调用方可传入的成员：租户、用户需由本服务认证后写入
+	defaultBaggageAllow = []string{BaggageClientApp, BaggageExperiment + "*"}
+	// defaultBaggageFields 复制到 Span 属性和日志字段的成员
+	defaultBaggageFields = []string{BaggageTenantID, BaggageUserID, BaggageClientApp}
+)
+
+// BaggageConfig Baggage 策略
+type BaggageConfig struct {
+	Allow   []string // 非受信调用方可传入的成员，支持 * 后缀前缀匹配（如 exp.*），默认 client.app,exp.*
+	Trusted []string // 受信调用方网段（CIDR 或 IP），其 Baggage 全部接受，如内部服务 10.0.0.0/8
+	Fields  []string // 复制到 Span 属性和日志字段的成员，默认 tenant.id,user.id,client.app
+}
+
+// baggagePolicy 生效的 Baggage 策略
+type baggagePolicy struct {
+	allow   []string
+	trusted []*net.IPNet
+	fields  []string
+}
+
+var policy atomic.Pointer[baggagePolicy]
+
+func init() {
+	_ = configureBaggage(BaggageConfig{})
+}
+
+// configureBaggage 设置 Baggage 策略，网段格式错误时返回错误
+func configureBaggage(config BaggageConfig) error {
+	p := &baggagePolicy{allow: config.Allow, fields: config.Fields}
+	if p.allow == nil {
+		p.allow = defaultBaggageAllow
+	}
+	if p.fields == nil {
+		p.fields = defaultBaggageFields
+	}
+	trusted, err := parseNetworks(config.Trusted)
+	if err != nil {
+		return err
+	}
+	p.trusted = trusted
+	policy.Store(p)
+	return nil
+}
+
+// WithTenantID 写入租户 ID Baggage（下游服务通过 TenantID 读取）
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return SetBaggage(ctx, BaggageTenantID, tenantID)
+}
+
+// TenantID 返回 Baggage 中的租户 ID
+func TenantID(ctx context.Context) string {
+	return GetBaggage(ctx, BaggageTenantID)
+}
+
+// WithUserID 写入用户 ID Baggage
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return SetBaggage(ctx, BaggageUserID, userID)
+}
+
+// UserID 返回 Baggage 中的用户 ID
+func UserID(ctx context.Context) string {
+	return GetBaggage(ctx, BaggageUserID)
+}
+
+// WithClientApp 写入调用方应用 Baggage
+func WithClientApp(ctx context.Context, app string) context.Context {
+	return SetBaggage(ctx, BaggageClientApp, app)
+}
+
+// ClientApp 返回 Baggage 中的调用方应用
+func ClientApp(ctx context.Context) string {
+	return GetBaggage(ctx, BaggageClientApp)
+}
+
+// WithExperiment 写入实验标记，如 WithExperiment(ctx, "new_search", "b")
+func WithExperiment(ctx context.Context, name, variant string) context.Context {
+	return SetBaggage(ctx, BaggageExperiment+name, variant)
+}
+
+// Experiment 返回实验标记的分组，未设置返回空
+func Experiment(ctx context.Context, name string) string {
+	return GetBaggage(ctx, BaggageExperiment+name)
+}
+
+// Experiments 返回全部实验标记（键为实验名）
+func Experiments(ctx context.Context) map[string]string {
+	out := make(map[string]string)
+	for _, m := range baggage.FromContext(ctx).Members() {
+		if name, ok := strings.CutPrefix(m.Key(), BaggageExperiment); ok {
+			out[name] = m.Value()
+		}
+	}
+	return out
+}
+
+// SetBaggage 写入 Baggage 成员，随出站请求传播到下游；空值删除该成员，键名非法时忽略
+// 成员在 Fields 中时同时写入日志上下文字段
+func SetBaggage(ctx context.Context, key, value string) context.Context {
+	b := baggage.FromContext(ctx)
+	if value == "" {
+		return baggage.ContextWithBaggage(ctx, b.DeleteMember(key))
+	}
+
+	m, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx
+	}
+	if b, err = b.SetMember(m); err != nil {
+		return ctx
+	}
+	ctx = baggage.ContextWithBaggage(ctx, b)
+	if matchAny(policy.Load().fields, key) {
+		ctx = logx.ContextWithFields(ctx, logx.Field(key, value))
+	}
+	return ctx
+}
+
+// GetBaggage 返回 Baggage 成员值
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// AcceptBaggage 处理入站请求的 Baggage：非受信调用方只保留 Allow 中的成员，
+// 并将 Fields 中的成员写入日志上下文字段（middleware.Trace 提取上下文后调用）
+// remoteAddr 为直连对端地址（http.Request.RemoteAddr），不使用可伪造的 X-Forwarded-For
+func AcceptBaggage(ctx context.Context, remoteAddr string) context.Context {
+	b := baggage.FromContext(ctx)
+	if b.Len() == 0 {
+		return ctx
+	}
+
+	p := policy.Load()
+	if !p.isTrusted(remoteAddr) {
+		for _, m := range b.Members() {
+			if !matchAny(p.allow, m.Key()) {
+				b = b.DeleteMember(m.Key())
+			}
+		}
+		ctx = baggage.ContextWithBaggage(ctx, b)
+	}
+
+	var fields []logx.LogField
+	for _, m := range b.Members() {
+		if matchAny(p.fields, m.Key()) {
+			fields = append(fields, logx.Field(m.Key(), m.Value()))
+		}
+	}
+	if len(fields) > 0 {
+		ctx = logx.ContextWithFields(ctx, fields...)
+	}
+	return ctx
+}
+
+// isTrusted 对端地址是否在受信网段
+func (p *baggagePolicy) isTrusted(remoteAddr string) bool {
+	return containsAddr(p.trusted, remoteAddr)
+}
+
+// matchAny 键名是否匹配规则（精确匹配，或以 * 结尾的前缀匹配）
+func matchAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if pattern == key {
+			return true
+		}
+	}
+	return false
+}
+
+// baggageProcessor 创建 Span 时将 Fields 中的 Baggage 成员复制为 Span 属性
+type baggageProcessor struct{}
+
+func (baggageProcessor) OnStart(ctx context.Context, s sdktrace.ReadWriteSpan) {
+	fields := policy.Load().fields
+	for _, m := range baggage.FromContext(ctx).Members() {
+		if matchAny(fields, m.Key()) {
+			s.SetAttributes(attribute.String(m.Key(), m.Value()))
+		}
+	}
+}
+
+func (baggageProcessor) OnEnd(sdktrace.ReadOnlySpan)      {}
+func (baggageProcessor) Shutdown(context.Context) error   { return nil }
+func (baggageProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,120 @@
+package trace
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// inbound 模拟从请求头提取的 Baggage
+func inbound(t *testing.T, header string) context.Context {
+	t.Helper()
+	b, err := baggage.Parse(header)
+	if err != nil {
+		t.Fatalf("baggage.Parse() error = %v", err)
+	}
+	return baggage.ContextWithBaggage(context.Background(), b)
+}
+
+func TestAcceptBaggage(t *testing.T) {
+	const header = "tenant.id=t1,user.id=u1,client.app=web,exp.new_search=b,debug=1"
+
+	tests := []struct {
+		name       string
+		config     BaggageConfig
+		remoteAddr string
+		want       string // 保留的成员（排序后）
+	}{
+		{"默认只接受应用和实验标记", BaggageConfig{}, "203.0.113.5:4312", "client.app=web,exp.new_search=b"},
+		{"受信网段全部接受", BaggageConfig{Trusted: []string{"10.0.0.0/8"}}, "10.1.2.3:5555",
+			"client.app=web,debug=1,exp.new_search=b,tenant.id=t1,user.id=u1"},
+		{"受信单个 IP", BaggageConfig{Trusted: []string{"10.1.2.3"}}, "10.1.2.4:5555", "client.app=web,exp.new_search=b"},
+		{"自定义允许列表", BaggageConfig{Allow: []string{"debug"}}, "203.0.113.5:4312", "debug=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := configureBaggage(tt.config); err != nil {
+				t.Fatalf("configureBaggage() error = %v", err)
+			}
+			defer configureBaggage(BaggageConfig{})
+
+			ctx := AcceptBaggage(inbound(t, header), tt.remoteAddr)
+			if got := sortedBaggage(ctx); got != tt.want {
+				t.Errorf("baggage = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if err := configureBaggage(BaggageConfig{Trusted: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("configureBaggage(invalid cidr) error = nil")
+	}
+}
+
+// sortedBaggage 按键名排序输出，便于比较
+func sortedBaggage(ctx context.Context) string {
+	members := baggage.FromContext(ctx).Members()
+	keys := make([]string, 0, len(members))
+	for _, m := range members {
+		keys = append(keys, m.Key()+"="+m.Value())
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func TestBaggageHelpers(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithTenantID(ctx, "t1")
+	ctx = WithUserID(ctx, "u1")
+	ctx = WithClientApp(ctx, "ios")
+	ctx = WithExperiment(ctx, "new_search", "b")
+	ctx = WithExperiment(ctx, "dark_mode", "on")
+
+	if TenantID(ctx) != "t1" || UserID(ctx) != "u1" || ClientApp(ctx) != "ios" {
+		t.Errorf("tenant/user/app = %s/%s/%s", TenantID(ctx), UserID(ctx), ClientApp(ctx))
+	}
+	if exps := Experiments(ctx); len(exps) != 2 || exps["new_search"] != "b" || Experiment(ctx, "dark_mode") != "on" {
+		t.Errorf("experiments = %v", exps)
+	}
+
+	// 值包含分隔符时按百分号编码传播
+	ctx = WithClientApp(ctx, "web, v2")
+	if ClientApp(ctx) != "web, v2" {
+		t.Errorf("ClientApp() = %q", ClientApp(ctx))
+	}
+
+	ctx = WithUserID(ctx, "")
+	if UserID(ctx) != "" {
+		t.Errorf("UserID() after delete = %q", UserID(ctx))
+	}
+}
+
+func TestBaggageProcessor(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(baggageProcessor{}),
+		sdktrace.WithSyncer(exporter),
+	)
+	defer tp.Shutdown(context.Background())
+
+	ctx := WithTenantID(context.Background(), "t1")
+	ctx = WithExperiment(ctx, "new_search", "b")
+	_, span := tp.Tracer("test").Start(ctx, "GET /api/v1/category")
+	span.End()
+
+	attrs := map[string]string{}
+	for _, kv := range exporter.GetSpans()[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+	}
+	if attrs[BaggageTenantID] != "t1" {
+		t.Errorf("tenant.id attribute = %q, want t1", attrs[BaggageTenantID])
+	}
+	if _, ok := attrs["exp.new_search"]; ok {
+		t.Error("exp.new_search is not in default Fields and must not be copied")
+	}
+}
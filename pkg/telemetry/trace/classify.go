@@ -0,0 +1,86 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"idrm/pkg/errorx"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 错误属性
+const (
+	AttrErrorCode     = "error.code"
+	AttrErrorCategory = "error.category"
+)
+
+// 错误分类（按 errorx 错误码区间）
+const (
+	ErrorCategorySystem   = "system"   // 10000-19999 及非 errorx 错误：标记 Span 为 Error
+	ErrorCategoryParam    = "param"    // 20000-29999
+	ErrorCategoryBusiness = "business" // 30000-39999
+	ErrorCategoryAuth     = "auth"     // 40000-49999
+	ErrorCategoryCanceled = "canceled" // 调用方取消请求（context.Canceled）
+)
+
+// ClassifyError 返回错误码及分类
+// 非 errorx 错误按系统错误处理（与 response.Error 一致，错误码为 errorx.ErrCodeSystem）
+func ClassifyError(err error) (code int, category string) {
+	if errors.Is(err, context.Canceled) {
+		return errorx.ErrCodeSystem, ErrorCategoryCanceled
+	}
+
+	codeErr, ok := errorx.FromError(err)
+	if !ok {
+		return errorx.ErrCodeSystem, ErrorCategorySystem
+	}
+
+	code = codeErr.GetCode()
+	switch code / 10000 {
+	case errorx.ErrCodeParam / 10000:
+		return code, ErrorCategoryParam
+	case errorx.ErrCodeBusiness / 10000:
+		return code, ErrorCategoryBusiness
+	case errorx.ErrCodeAuth / 10000:
+		return code, ErrorCategoryAuth
+	default:
+		return code, ErrorCategorySystem
+	}
+}
+
+// IsSystemError 是否为系统错误（需要告警和排查的错误）
+func IsSystemError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, category := ClassifyError(err)
+	return category == ErrorCategorySystem
+}
+
+// Run 在 Internal Span 中执行 fn，结束时按 End 规则记录错误
+// fn 发生 panic 时 Span 标记为 Error 并记录堆栈，结束 Span 后继续向上 panic（由 Recovery 中间件处理）
+//
+//	err := trace.Run(ctx, "category.Publish", func(ctx context.Context) error {
+//	    return l.publish(ctx, id)
+//	}, attribute.Int64("category.id", id))
+func Run(ctx context.Context, name string, fn func(ctx context.Context) error, attrs ...attribute.KeyValue) (err error) {
+	ctx, span := Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+	defer func() {
+		if p := recover(); p != nil {
+			span.RecordError(fmt.Errorf("panic: %v", p), trace.WithStackTrace(true))
+			span.SetAttributes(
+				attribute.Int(AttrErrorCode, errorx.ErrCodeSystem),
+				attribute.String(AttrErrorCategory, ErrorCategorySystem),
+			)
+			span.SetStatus(codes.Error, fmt.Sprintf("panic: %v", p))
+			span.End()
+			panic(p)
+		}
+		End(span, err)
+	}()
+	return fn(ctx)
+}
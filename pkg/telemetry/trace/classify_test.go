@@ -0,0 +1,116 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"idrm/pkg/errorx"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useTestTracer 将默认实例替换为内存导出的实例
+func useTestTracer(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	restore := SetTracerProvider(tp)
+	t.Cleanup(func() {
+		restore()
+		_ = tp.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantCode     int
+		wantCategory string
+		wantStatus   codes.Code
+	}{
+		{"数据库错误", errorx.NewWithCode(errorx.ErrCodeDatabase), errorx.ErrCodeDatabase, ErrorCategorySystem, codes.Error},
+		{"包装的外部服务错误", fmt.Errorf("call: %w", errorx.Wrap(errorx.ErrCodeExternal, errors.New("eof"))),
+			errorx.ErrCodeExternal, ErrorCategorySystem, codes.Error},
+		{"普通错误按系统错误", errors.New("boom"), errorx.ErrCodeSystem, ErrorCategorySystem, codes.Error},
+		{"参数错误", errorx.NewWithCode(errorx.ErrCodeParamInvalid), errorx.ErrCodeParamInvalid, ErrorCategoryParam, codes.Unset},
+		{"数据不存在", errorx.NewWithCode(errorx.ErrCodeNotFound), errorx.ErrCodeNotFound, ErrorCategoryBusiness, codes.Unset},
+		{"Token 过期", errorx.NewWithCode(errorx.ErrCodeTokenExpired), errorx.ErrCodeTokenExpired, ErrorCategoryAuth, codes.Unset},
+		{"请求取消", context.Canceled, errorx.ErrCodeSystem, ErrorCategoryCanceled, codes.Unset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, category := ClassifyError(tt.err)
+			if code != tt.wantCode || category != tt.wantCategory {
+				t.Fatalf("ClassifyError() = %d/%s, want %d/%s", code, category, tt.wantCode, tt.wantCategory)
+			}
+
+			exporter := useTestTracer(t)
+			_, span := Start(context.Background(), "op")
+			End(span, tt.err)
+
+			got := exporter.GetSpans()[0]
+			if got.Status.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", got.Status.Code, tt.wantStatus)
+			}
+			attrs := map[string]string{}
+			for _, kv := range got.Attributes {
+				attrs[string(kv.Key)] = kv.Value.Emit()
+			}
+			if attrs[AttrErrorCode] != fmt.Sprint(tt.wantCode) || attrs[AttrErrorCategory] != tt.wantCategory {
+				t.Errorf("attributes = %v", attrs)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	exporter := useTestTracer(t)
+
+	// 正常返回：Span 为 fn 中 ctx 的当前 Span
+	err := Run(context.Background(), "ok", func(ctx context.Context) error {
+		if GetSpanID(ctx) == "" {
+			t.Error("fn ctx has no span")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// 业务错误原样返回，不标记 Error
+	notFound := errorx.NewWithCode(errorx.ErrCodeNotFound)
+	if err := Run(context.Background(), "not_found", func(context.Context) error { return notFound }); err != notFound {
+		t.Fatalf("Run() error = %v, want %v", err, notFound)
+	}
+
+	// panic 继续向上抛出，Span 已结束并标记 Error
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("recover() = %v, want boom", p)
+			}
+		}()
+		_ = Run(context.Background(), "panic", func(context.Context) error { panic("boom") })
+	}()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("spans = %d, want 3", len(spans))
+	}
+	want := []codes.Code{codes.Ok, codes.Unset, codes.Error}
+	for i, s := range spans {
+		if s.Status.Code != want[i] {
+			t.Errorf("span %s status = %v, want %v", s.Name, s.Status.Code, want[i])
+		}
+	}
+	if len(spans[2].Events) == 0 || spans[2].Events[0].Name != "exception" {
+		t.Errorf("panic span events = %v, want exception", spans[2].Events)
+	}
+}
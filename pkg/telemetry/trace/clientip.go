@@ -0,0 +1,98 @@
+package trace
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// trustedProxies 受信反向代理网段，只有来自这些地址的连接才读取 X-Forwarded-For / X-Real-IP
+var trustedProxies atomic.Pointer[[]*net.IPNet]
+
+// configureProxies 设置受信反向代理网段，格式错误时返回错误
+func configureProxies(cidrs []string) error {
+	nets, err := parseNetworks(cidrs)
+	if err != nil {
+		return err
+	}
+	trustedProxies.Store(&nets)
+	return nil
+}
+
+// ClientIP 返回客户端 IP（审计日志、Span 属性共用）
+// 默认为连接地址；连接来自受信代理（TraceConfig.TrustedProxies）时，取 X-Forwarded-For 中
+// 从右往左第一个非受信代理的地址，没有 X-Forwarded-For 时取 X-Real-IP。
+// 客户端可任意设置这两个请求头，不能无条件信任
+func ClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+
+	var proxies []*net.IPNet
+	if p := trustedProxies.Load(); p != nil {
+		proxies = *p
+	}
+	if !containsAddr(proxies, remote) {
+		return remote
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			if i == 0 || !containsAddr(proxies, hop) {
+				return hop
+			}
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return remote
+}
+
+// parseNetworks 解析网段列表，单个 IP 视为 /32（IPv6 为 /128）
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// containsAddr 地址（IP 或 host:port）是否在网段内
+func containsAddr(nets []*net.IPNet, addr string) bool {
+	if len(nets) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
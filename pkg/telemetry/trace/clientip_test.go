@@ -0,0 +1,41 @@
+package trace
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	if err := configureProxies([]string{"10.0.0.0/8", "192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+	defer configureProxies(nil)
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"直连", "203.0.113.7:4321", nil, "203.0.113.7"},
+		{"非受信连接忽略 X-Forwarded-For", "203.0.113.7:4321", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.7"},
+		{"非受信连接忽略 X-Real-IP", "203.0.113.7:4321", map[string]string{"X-Real-IP": "1.2.3.4"}, "203.0.113.7"},
+		{"受信代理取最右侧非代理地址", "192.0.2.1:80", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 10.1.1.1"}, "198.51.100.9"},
+		{"全部为受信代理取最左侧", "192.0.2.1:80", map[string]string{"X-Forwarded-For": "10.0.0.2, 10.1.1.1"}, "10.0.0.2"},
+		{"受信代理的 X-Real-IP", "10.0.0.5:80", map[string]string{"X-Real-IP": "198.51.100.9"}, "198.51.100.9"},
+		{"受信代理无转发头", "10.0.0.5:80", nil, "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if got := ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package trace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // 注册 gRPC gzip 压缩
+)
+
+// 导出器类型（TraceConfig.Batcher）
+const (
+	BatcherOTLP     = "otlp"     // 同 otlpgrpc
+	BatcherOTLPGRPC = "otlpgrpc" // OTLP gRPC，默认 localhost:4317
+	BatcherOTLPHTTP = "otlphttp" // OTLP HTTP，默认 localhost:4318
+	BatcherJaeger   = "jaeger"   // Jaeger 原生支持 OTLP，使用 OTLP gRPC 发送
+	BatcherZipkin   = "zipkin"   // Zipkin v2 JSON，默认 http://localhost:9411/api/v2/spans
+	BatcherStdout   = "stdout"   // 控制台格式化输出，用于本地调试
+	BatcherFile     = "file"     // JSON Lines 文件，用于离线分析
+)
+
+// 各导出器默认地址
+const (
+	defaultGRPCEndpoint   = "localhost:4317"
+	defaultHTTPEndpoint   = "localhost:4318"
+	defaultZipkinEndpoint = "http://localhost:9411/api/v2/spans"
+	defaultTraceFile      = "logs/trace.jsonl"
+
+	// CompressionGzip gzip 压缩（stdout/file 不支持）
+	CompressionGzip = "gzip"
+)
+
+// newExporter 按 Batcher 创建导出器
+func newExporter(ctx context.Context, config TraceConfig) (sdktrace.SpanExporter, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(config.Batcher) {
+	case "", BatcherOTLP, BatcherOTLPGRPC, BatcherJaeger:
+		return newGRPCExporter(ctx, config, tlsConfig)
+	case BatcherOTLPHTTP:
+		return newHTTPExporter(ctx, config, tlsConfig)
+	case BatcherZipkin:
+		return newZipkinExporter(config, tlsConfig)
+	case BatcherStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case BatcherFile:
+		return newFileExporter(config)
+	default:
+		return nil, fmt.Errorf("unknown trace batcher: %s", config.Batcher)
+	}
+}
+
+// newGRPCExporter OTLP gRPC 导出器
+func newGRPCExporter(ctx context.Context, config TraceConfig, tlsConfig *tls.Config) (sdktrace.SpanExporter, error) {
+	endpoint := valueOr(config.Endpoint, defaultGRPCEndpoint)
+
+	// 不阻塞等待连接，gRPC 在后台连接和重连；重试由 resilientExporter 负责
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{Enabled: false}),
+	}
+	if strings.Contains(endpoint, "://") {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+	} else {
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+	}
+	switch {
+	case tlsConfig != nil:
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	case !strings.HasPrefix(endpoint, "https://"):
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(config.Headers))
+	}
+	if config.Compression == CompressionGzip {
+		opts = append(opts, otlptracegrpc.WithCompressor(CompressionGzip))
+	}
+
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// newHTTPExporter OTLP HTTP 导出器，Endpoint 可为 host:port 或完整 URL
+func newHTTPExporter(ctx context.Context, config TraceConfig, tlsConfig *tls.Config) (sdktrace.SpanExporter, error) {
+	endpoint := valueOr(config.Endpoint, defaultHTTPEndpoint)
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}),
+	}
+	if strings.Contains(endpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	} else {
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
+	switch {
+	case tlsConfig != nil:
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	case !strings.HasPrefix(endpoint, "https://"):
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+	}
+	if config.Compression == CompressionGzip {
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
+
+// newZipkinExporter Zipkin 导出器，请求头和压缩通过 HTTP Transport 实现
+func newZipkinExporter(config TraceConfig, tlsConfig *tls.Config) (sdktrace.SpanExporter, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &zipkinTransport{
+			next:    transport,
+			headers: config.Headers,
+			gzip:    config.Compression == CompressionGzip,
+		},
+	}
+	return zipkin.New(valueOr(config.Endpoint, defaultZipkinEndpoint), zipkin.WithClient(client))
+}
+
+// zipkinTransport 附加请求头并按需 gzip 压缩请求体
+type zipkinTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+	gzip    bool
+}
+
+func (t *zipkinTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for k, v := range t.headers {
+		r.Header.Set(k, v)
+	}
+
+	if t.gzip && r.Body != nil {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := io.Copy(zw, r.Body); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(&buf)
+		r.ContentLength = int64(buf.Len())
+		r.Header.Set("Content-Encoding", CompressionGzip)
+	}
+
+	return t.next.RoundTrip(r)
+}
+
+// fileExporter 以 JSON Lines 追加写入文件的导出器，关闭时关闭文件
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+// newFileExporter 文件导出器，每个 Span 一行 JSON
+func newFileExporter(config TraceConfig) (sdktrace.SpanExporter, error) {
+	path := valueOr(config.FilePath, defaultTraceFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &fileExporter{SpanExporter: exporter, file: file}, nil
+}
+
+// Shutdown 关闭导出器和文件
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	err := e.SpanExporter.Shutdown(ctx)
+	if cerr := e.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// newTLSConfig 按配置创建 TLS 配置，未启用 TLS 时返回 nil
+func newTLSConfig(config TraceConfig) (*tls.Config, error) {
+	if !config.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.TLSSkipVerify,
+	}
+	if config.CAFile != "" {
+		ca, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read trace ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid trace ca file: %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load trace client cert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// valueOr 返回 value，为空时返回默认值
+func valueOr(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
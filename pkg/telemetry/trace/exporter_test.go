@@ -0,0 +1,129 @@
+package trace
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// exportOne 用导出器同步导出一个 Span
+func exportOne(t *testing.T, exporter sdktrace.SpanExporter) {
+	t.Helper()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := tp.Tracer("test").Start(context.Background(), "GET /api/v1/category")
+	span.End()
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestNewExporterHTTP(t *testing.T) {
+	type request struct {
+		path, auth, encoding string
+		size                 int
+	}
+
+	tests := []struct {
+		name    string
+		batcher string
+		path    string
+	}{
+		{"OTLP HTTP", BatcherOTLPHTTP, "/v1/traces"},
+		{"Zipkin", BatcherZipkin, "/api/v2/spans"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(chan request, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := io.Reader(r.Body)
+				if r.Header.Get("Content-Encoding") == CompressionGzip {
+					zr, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("gzip body: %v", err)
+						return
+					}
+					body = zr
+				}
+				data, _ := io.ReadAll(body)
+				got <- request{r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Content-Encoding"), len(data)}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer srv.Close()
+
+			exporter, err := newExporter(context.Background(), TraceConfig{
+				Batcher:     tt.batcher,
+				Endpoint:    srv.URL + tt.path,
+				Headers:     map[string]string{"Authorization": "Bearer t"},
+				Compression: CompressionGzip,
+			})
+			if err != nil {
+				t.Fatalf("newExporter() error = %v", err)
+			}
+			exportOne(t, exporter)
+
+			req := <-got
+			if req.path != tt.path || req.auth != "Bearer t" || req.encoding != CompressionGzip || req.size == 0 {
+				t.Errorf("request = %+v", req)
+			}
+		})
+	}
+}
+
+func TestNewExporterFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace", "spans.jsonl")
+	for i := 0; i < 2; i++ {
+		exporter, err := newExporter(context.Background(), TraceConfig{Batcher: BatcherFile, FilePath: path})
+		if err != nil {
+			t.Fatalf("newExporter() error = %v", err)
+		}
+		exportOne(t, exporter)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	for scanner.Scan() {
+		var span struct{ Name string }
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil || span.Name != "GET /api/v1/category" {
+			t.Errorf("line %d = %s, err = %v", lines, scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("lines = %d, want 2 (file must be appended)", lines)
+	}
+}
+
+func TestNewExporterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TraceConfig
+	}{
+		{"未知导出器", TraceConfig{Batcher: "jaeger-thrift"}},
+		{"CA文件不存在", TraceConfig{Batcher: BatcherOTLPHTTP, TLSEnabled: true, CAFile: "/nonexistent/ca.pem"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newExporter(context.Background(), tt.config); err == nil {
+				t.Error("newExporter() error = nil, want error")
+			}
+		})
+	}
+}
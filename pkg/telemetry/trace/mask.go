@@ -0,0 +1,72 @@
+package trace
+
+import (
+	"context"
+
+	"idrm/pkg/telemetry/mask"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// maskExporter 导出前按 mask 键名规则脱敏 Span 及事件属性
+// 在导出层处理可以覆盖直接调用 span.SetAttributes 的场景
+type maskExporter struct {
+	sdktrace.SpanExporter
+}
+
+// newMaskExporter 包装 Exporter
+func newMaskExporter(exporter sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &maskExporter{SpanExporter: exporter}
+}
+
+// ExportSpans 脱敏后导出
+func (e *maskExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	masked := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		masked[i] = maskSpan(span)
+	}
+	return e.SpanExporter.ExportSpans(ctx, masked)
+}
+
+// maskedSpan 替换属性和事件的只读 Span
+type maskedSpan struct {
+	sdktrace.ReadOnlySpan
+	attrs  []attribute.KeyValue
+	events []sdktrace.Event
+}
+
+func (s *maskedSpan) Attributes() []attribute.KeyValue {
+	return s.attrs
+}
+
+func (s *maskedSpan) Events() []sdktrace.Event {
+	return s.events
+}
+
+// maskSpan 脱敏单个 Span，无需脱敏时返回原 Span
+func maskSpan(span sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	attrs, changed := mask.Attributes(span.Attributes())
+
+	events := span.Events()
+	var maskedEvents []sdktrace.Event
+	for i, event := range events {
+		eventAttrs, ok := mask.Attributes(event.Attributes)
+		if !ok {
+			continue
+		}
+		if maskedEvents == nil {
+			maskedEvents = make([]sdktrace.Event, len(events))
+			copy(maskedEvents, events)
+		}
+		maskedEvents[i].Attributes = eventAttrs
+	}
+
+	if !changed && maskedEvents == nil {
+		return span
+	}
+	if maskedEvents == nil {
+		maskedEvents = events
+	}
+	return &maskedSpan{ReadOnlySpan: span, attrs: attrs, events: maskedEvents}
+}
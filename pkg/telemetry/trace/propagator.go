@@ -0,0 +1,48 @@
+package trace
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// 传播格式（与 OTEL_PROPAGATORS 取值一致）
+const (
+	PropagatorTraceContext = "tracecontext" // W3C traceparent/tracestate
+	PropagatorBaggage      = "baggage"      // W3C baggage
+	PropagatorB3           = "b3"           // B3 单请求头（b3）
+	PropagatorB3Multi      = "b3multi"      // B3 多请求头（X-B3-TraceId 等）
+	PropagatorJaeger       = "jaeger"       // uber-trace-id
+)
+
+// defaultPropagators 默认传播格式
+var defaultPropagators = []string{PropagatorTraceContext, PropagatorBaggage}
+
+// newPropagator 按名称组合 Propagator：注入时写出所有格式，提取时后配置的格式优先
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = defaultPropagators
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case PropagatorJaeger:
+			propagators = append(propagators, jaeger.Jaeger{})
+		default:
+			return nil, fmt.Errorf("unknown trace propagator: %s", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
@@ -0,0 +1,59 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewPropagator(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name       string
+		names      []string
+		inbound    map[string]string
+		wantHeader string // 出站请求应写出的请求头
+	}{
+		{"默认 W3C", nil, map[string]string{"traceparent": "00-" + traceID + "-" + spanID + "-01"}, "traceparent"},
+		{"B3 单请求头", []string{"b3"}, map[string]string{"b3": traceID + "-" + spanID + "-1"}, "b3"},
+		{"B3 多请求头", []string{"tracecontext", "b3multi"},
+			map[string]string{"X-B3-TraceId": traceID, "X-B3-SpanId": spanID, "X-B3-Sampled": "1"}, "X-B3-TraceId"},
+		{"Jaeger", []string{"jaeger"}, map[string]string{"uber-trace-id": traceID + ":" + spanID + ":0:1"}, "uber-trace-id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPropagator(tt.names)
+			if err != nil {
+				t.Fatalf("newPropagator() error = %v", err)
+			}
+
+			in := http.Header{}
+			for k, v := range tt.inbound {
+				in.Set(k, v)
+			}
+			ctx := p.Extract(context.Background(), propagation.HeaderCarrier(in))
+			sc := trace.SpanContextFromContext(ctx)
+			if sc.TraceID().String() != traceID || sc.SpanID().String() != spanID || !sc.IsRemote() {
+				t.Fatalf("extracted = %s/%s remote=%v", sc.TraceID(), sc.SpanID(), sc.IsRemote())
+			}
+
+			out := http.Header{}
+			p.Inject(ctx, propagation.HeaderCarrier(out))
+			if out.Get(tt.wantHeader) == "" {
+				t.Errorf("injected headers = %v, want %s", out, tt.wantHeader)
+			}
+		})
+	}
+
+	if _, err := newPropagator([]string{"xray"}); err == nil {
+		t.Error("newPropagator(xray) error = nil, want unknown propagator")
+	}
+}
@@ -0,0 +1,236 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Collector 不可用时的 Span 处理策略
+const (
+	DownPolicyBuffer = "buffer" // 缓存到内存（超出容量丢弃最旧的），恢复后补发
+	DownPolicyDrop   = "drop"   // 直接丢弃
+)
+
+// 导出器连接状态
+const (
+	StateConnecting   = "connecting"   // 尚未成功导出
+	StateConnected    = "connected"    // 最近一次导出成功
+	StateDisconnected = "disconnected" // 最近一次导出失败，后台重连中
+	StateDisabled     = "disabled"     // 未启用链路追踪
+)
+
+// 重连默认值
+const (
+	defaultBufferSize = 2048
+	minReconnect      = time.Second
+	maxReconnect      = time.Minute
+)
+
+// Health 链路追踪导出状态，用于健康检查
+type Health struct {
+	State     string     `json:"state"`
+	Batcher   string     `json:"batcher,omitempty"`
+	Endpoint  string     `json:"endpoint,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Since     *time.Time `json:"since,omitempty"` // 进入当前状态的时间
+	Buffered  int        `json:"buffered"`        // 等待补发的 Span 数
+	Dropped   uint64     `json:"dropped"`         // 累计丢弃的 Span 数
+	Exported  uint64     `json:"exported"`        // 累计导出的 Span 数
+}
+
+// resilientExporter 容忍 Collector 不可用的导出器
+// 导出失败后进入 disconnected 状态，按策略缓存或丢弃 Span，后台按指数退避重试，
+// 重试期间 BatchSpanProcessor 的导出立即返回，不会阻塞
+type resilientExporter struct {
+	next       sdktrace.SpanExporter
+	policy     string
+	bufferSize int
+
+	mu        sync.Mutex
+	health    Health
+	buffer    []sdktrace.ReadOnlySpan
+	backoff   time.Duration
+	nextRetry time.Time
+
+	dropped  atomic.Uint64
+	exported atomic.Uint64
+
+	// exportMu 串行化对下游导出器的调用（BatchSpanProcessor 与后台重连）
+	exportMu sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+
+	// shutdownOnce TracerProvider 关闭和 Provider.Close 都可能调用 Shutdown，只执行一次
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// newResilientExporter 创建导出器并启动后台重连
+func newResilientExporter(next sdktrace.SpanExporter, config TraceConfig) *resilientExporter {
+	bufferSize := config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	policy := config.DownPolicy
+	if policy != DownPolicyDrop {
+		policy = DownPolicyBuffer
+	}
+
+	now := time.Now()
+	e := &resilientExporter{
+		next:       next,
+		policy:     policy,
+		bufferSize: bufferSize,
+		health: Health{
+			State:    StateConnecting,
+			Batcher:  config.Batcher,
+			Endpoint: config.Endpoint,
+			Since:    &now,
+		},
+		backoff: minReconnect,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go e.loop()
+	return e
+}
+
+// ExportSpans 导出 Span；Collector 不可用期间按策略缓存或丢弃，始终返回 nil 避免 SDK 重复报错
+func (e *resilientExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	if e.health.State == StateDisconnected && time.Now().Before(e.nextRetry) {
+		e.keepLocked(spans)
+		e.mu.Unlock()
+		return nil
+	}
+	e.mu.Unlock()
+
+	e.export(ctx, spans)
+	return nil
+}
+
+// export 连同缓存的 Span 一起导出，更新连接状态
+func (e *resilientExporter) export(ctx context.Context, spans []sdktrace.ReadOnlySpan) bool {
+	e.exportMu.Lock()
+	defer e.exportMu.Unlock()
+
+	e.mu.Lock()
+	batch := append(e.buffer, spans...)
+	e.buffer = nil
+	e.mu.Unlock()
+
+	if len(batch) == 0 {
+		return true
+	}
+
+	err := e.next.ExportSpans(ctx, batch)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil {
+		e.exported.Add(uint64(len(batch)))
+		e.backoff = minReconnect
+		if e.health.State != StateConnected {
+			if e.health.State == StateDisconnected {
+				logx.Infof("链路追踪 Collector 已恢复 [endpoint=%s]", e.health.Endpoint)
+			}
+			e.setStateLocked(StateConnected, "")
+		}
+		return true
+	}
+
+	if e.health.State != StateDisconnected {
+		logx.Errorf("链路追踪导出失败，后台重连 [endpoint=%s]: %v", e.health.Endpoint, err)
+		e.setStateLocked(StateDisconnected, err.Error())
+	} else {
+		e.health.LastError = err.Error()
+		e.backoff = min(e.backoff*2, maxReconnect)
+	}
+	e.nextRetry = time.Now().Add(e.backoff)
+	e.keepLocked(batch)
+	return false
+}
+
+// keepLocked 按策略缓存 Span，超出容量丢弃最旧的
+func (e *resilientExporter) keepLocked(spans []sdktrace.ReadOnlySpan) {
+	if e.policy == DownPolicyDrop {
+		e.dropped.Add(uint64(len(spans)))
+		return
+	}
+	e.buffer = append(e.buffer, spans...)
+	if over := len(e.buffer) - e.bufferSize; over > 0 {
+		e.dropped.Add(uint64(over))
+		e.buffer = append(e.buffer[:0:0], e.buffer[over:]...)
+	}
+}
+
+// setStateLocked 切换状态
+func (e *resilientExporter) setStateLocked(state, lastError string) {
+	now := time.Now()
+	e.health.State = state
+	e.health.LastError = lastError
+	e.health.Since = &now
+}
+
+// loop 断开期间到达重试时间后补发缓存，没有缓存时等待下一批 Span 探测
+func (e *resilientExporter) loop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(minReconnect)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case now := <-ticker.C:
+			e.mu.Lock()
+			retry := e.health.State == StateDisconnected && len(e.buffer) > 0 && !now.Before(e.nextRetry)
+			e.mu.Unlock()
+			if retry {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				e.export(ctx, nil)
+				cancel()
+			}
+		}
+	}
+}
+
+// Health 返回当前状态
+func (e *resilientExporter) Health() Health {
+	e.mu.Lock()
+	h := e.health
+	h.Buffered = len(e.buffer)
+	e.mu.Unlock()
+
+	h.Dropped = e.dropped.Load()
+	h.Exported = e.exported.Load()
+	return h
+}
+
+// Shutdown 停止重连，尽力补发缓存后关闭下游导出器；重复调用返回首次的结果
+func (e *resilientExporter) Shutdown(ctx context.Context) error {
+	e.shutdownOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+
+		if !e.export(ctx, nil) {
+			e.mu.Lock()
+			lost := len(e.buffer)
+			e.buffer = nil
+			e.mu.Unlock()
+			e.dropped.Add(uint64(lost))
+			logx.Errorf("链路追踪关闭时丢弃未发送的 Span: %d", lost)
+		}
+		e.shutdownErr = e.next.Shutdown(ctx)
+	})
+	return e.shutdownErr
+}
@@ -0,0 +1,147 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// flakyExporter 可切换成功/失败的导出器
+type flakyExporter struct {
+	mu        sync.Mutex
+	down      bool
+	exported  int
+	shutdowns int
+}
+
+func (e *flakyExporter) setDown(down bool) {
+	e.mu.Lock()
+	e.down = down
+	e.mu.Unlock()
+}
+
+func (e *flakyExporter) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.exported
+}
+
+func (e *flakyExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.down {
+		return errors.New("connection refused")
+	}
+	e.exported += len(spans)
+	return nil
+}
+
+func (e *flakyExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdowns++
+	if e.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func spans(n int) []sdktrace.ReadOnlySpan {
+	stubs := make(tracetest.SpanStubs, n)
+	return stubs.Snapshots()
+}
+
+func TestResilientExporter(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       string
+		bufferSize   int
+		wantBuffered int
+		wantDropped  uint64
+		wantExported int // 恢复后累计导出
+	}{
+		{"缓存后补发", DownPolicyBuffer, 100, 5, 0, 6},
+		{"缓存超出容量丢弃最旧", DownPolicyBuffer, 3, 3, 2, 4},
+		{"丢弃策略", DownPolicyDrop, 100, 0, 5, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &flakyExporter{down: true}
+			e := newResilientExporter(next, TraceConfig{DownPolicy: tt.policy, BufferSize: tt.bufferSize})
+			defer e.Shutdown(context.Background())
+			ctx := context.Background()
+
+			if got := e.Health().State; got != StateConnecting {
+				t.Fatalf("initial state = %s", got)
+			}
+
+			// 首次失败后进入 disconnected，退避期内的导出立即返回
+			for i := 0; i < 5; i++ {
+				if err := e.ExportSpans(ctx, spans(1)); err != nil {
+					t.Fatalf("ExportSpans() error = %v", err)
+				}
+			}
+			h := e.Health()
+			if h.State != StateDisconnected || h.LastError == "" || h.Buffered != tt.wantBuffered || h.Dropped != tt.wantDropped {
+				t.Fatalf("health while down = %+v", h)
+			}
+
+			// 恢复后到达重试时间，缓存与新 Span 一起导出
+			next.setDown(false)
+			e.mu.Lock()
+			e.nextRetry = time.Time{}
+			e.mu.Unlock()
+			_ = e.ExportSpans(ctx, spans(1))
+
+			h = e.Health()
+			if h.State != StateConnected || h.Buffered != 0 || next.count() != tt.wantExported {
+				t.Errorf("health after recovery = %+v, exported = %d, want %d", h, next.count(), tt.wantExported)
+			}
+		})
+	}
+}
+
+func TestResilientExporterBackgroundRetry(t *testing.T) {
+	next := &flakyExporter{down: true}
+	e := newResilientExporter(next, TraceConfig{})
+	defer e.Shutdown(context.Background())
+
+	_ = e.ExportSpans(context.Background(), spans(2))
+	next.setDown(false)
+
+	// 没有新 Span 时后台在退避到期后补发缓存
+	deadline := time.Now().Add(5 * time.Second)
+	for e.Health().State != StateConnected {
+		if time.Now().After(deadline) {
+			t.Fatalf("health = %+v, want connected after background retry", e.Health())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if next.count() != 2 {
+		t.Errorf("exported = %d, want 2", next.count())
+	}
+}
+
+func TestResilientExporterShutdownTwice(t *testing.T) {
+	next := &flakyExporter{down: true}
+	e := newResilientExporter(next, TraceConfig{})
+	ctx := context.Background()
+
+	// TracerProvider 关闭和 Provider.Close 都会调用 Shutdown：第二次不 panic，返回首次的结果
+	first := e.Shutdown(ctx)
+	if first == nil {
+		t.Fatal("Shutdown() error = nil, want downstream error")
+	}
+	if err := e.Shutdown(ctx); err != first {
+		t.Errorf("second Shutdown() error = %v, want %v", err, first)
+	}
+	if next.shutdowns != 1 {
+		t.Errorf("downstream shutdowns = %d, want 1", next.shutdowns)
+	}
+}
@@ -0,0 +1,110 @@
+package trace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AttrURLPath 请求路径属性，按路由采样时优先匹配该属性，不存在时匹配 Span 名称
+const AttrURLPath = "url.path"
+
+// SamplingRule 按路由采样规则（前缀匹配，最长前缀优先）
+type SamplingRule struct {
+	Route string  // 路由前缀，如 /api/v1/catalog/publish
+	Ratio float64 // 采样率：1 总是采样，0 从不采样
+}
+
+// routeRule 预先创建采样器的路由规则
+type routeRule struct {
+	prefix  string
+	ratio   float64
+	sampler sdktrace.Sampler
+}
+
+// sampler 链路采样器
+//   - 上游已采样时总是遵循上游决策（parent-based），不在上游保留的 trace 中产生缺口
+//   - 其余本地根 Span（无父或上游未采样）先按路由规则采样
+//   - 未命中规则时遵循父 Span 的采样决策，无父 Span 时按 Sampler 比例采样
+//   - 启用尾部采样时，比例采样丢弃的 Span 改为只记录不导出，由 tailProcessor 决定是否保留
+type sampler struct {
+	rules    []routeRule
+	fallback sdktrace.Sampler
+	tail     bool
+}
+
+// newSampler 创建采样器
+func newSampler(ratio float64, rules []SamplingRule, tail bool) sdktrace.Sampler {
+	s := &sampler{
+		fallback: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)),
+		tail:     tail,
+	}
+	for _, rule := range rules {
+		s.rules = append(s.rules, routeRule{
+			prefix:  rule.Route,
+			ratio:   rule.Ratio,
+			sampler: sdktrace.TraceIDRatioBased(rule.Ratio),
+		})
+	}
+	sort.SliceStable(s.rules, func(i, j int) bool {
+		return len(s.rules[i].prefix) > len(s.rules[j].prefix)
+	})
+	return s
+}
+
+// ShouldSample 采样决策
+func (s *sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	localRoot := !parent.IsValid() || parent.IsRemote()
+
+	if localRoot && !parent.IsSampled() {
+		if rule := s.match(p); rule != nil {
+			result := rule.sampler.ShouldSample(p)
+			// 显式不采样的路由（如健康检查）不参与尾部采样
+			if result.Decision == sdktrace.Drop && s.tail && rule.ratio > 0 {
+				result.Decision = sdktrace.RecordOnly
+			}
+			return result
+		}
+	}
+
+	result := s.fallback.ShouldSample(p)
+	if result.Decision == sdktrace.Drop && s.tail && (localRoot || isRecordOnly(p)) {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+// isRecordOnly 本地父 Span 是否为尾部采样候选（只记录未采样）
+func isRecordOnly(p sdktrace.SamplingParameters) bool {
+	span := trace.SpanFromContext(p.ParentContext)
+	return span.IsRecording() && !span.SpanContext().IsSampled()
+}
+
+// match 按请求路径（或 Span 名称）匹配路由规则
+func (s *sampler) match(p sdktrace.SamplingParameters) *routeRule {
+	if len(s.rules) == 0 {
+		return nil
+	}
+	path := p.Name
+	for _, attr := range p.Attributes {
+		if attr.Key == AttrURLPath {
+			path = attr.Value.AsString()
+			break
+		}
+	}
+	for i := range s.rules {
+		if strings.HasPrefix(path, s.rules[i].prefix) {
+			return &s.rules[i]
+		}
+	}
+	return nil
+}
+
+// Description 采样器描述
+func (s *sampler) Description() string {
+	return fmt.Sprintf("RouteSampler{rules=%d,fallback=%s,tail=%v}", len(s.rules), s.fallback.Description(), s.tail)
+}
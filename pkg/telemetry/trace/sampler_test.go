@@ -0,0 +1,118 @@
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSampler(t *testing.T) {
+	s := newSampler(0, []SamplingRule{
+		{Route: "/api/v1/catalog/publish", Ratio: 1},
+		{Route: "/health", Ratio: 0},
+		{Route: "/api/v1/catalog", Ratio: 0},
+	}, true)
+
+	sampledParent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	unsampledParent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{1},
+		Remote:  true,
+	})
+
+	tests := []struct {
+		name   string
+		parent trace.SpanContext
+		span   string
+		path   string
+		want   sdktrace.SamplingDecision
+	}{
+		{"总是采样的路由", trace.SpanContext{}, "POST", "/api/v1/catalog/publish", sdktrace.RecordAndSample},
+		{"最长前缀优先", trace.SpanContext{}, "GET", "/api/v1/catalog/publish/1", sdktrace.RecordAndSample},
+		{"从不采样的路由不参与尾部采样", trace.SpanContext{}, "GET", "/health", sdktrace.Drop},
+		{"无路径属性时匹配Span名称", trace.SpanContext{}, "/health", "", sdktrace.Drop},
+		{"比例未命中转为尾部采样候选", trace.SpanContext{}, "GET", "/api/v1/category", sdktrace.RecordOnly},
+		{"上游已采样时不应用路由规则", sampledParent, "GET", "/health", sdktrace.RecordAndSample},
+		{"上游未采样时路由规则仍生效", unsampledParent, "POST", "/api/v1/catalog/publish", sdktrace.RecordAndSample},
+		{"遵循上游采样决策", sampledParent, "GET", "/api/v1/category", sdktrace.RecordAndSample},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.parent.IsValid() {
+				ctx = trace.ContextWithRemoteSpanContext(ctx, tt.parent)
+			}
+			var attrs []attribute.KeyValue
+			if tt.path != "" {
+				attrs = append(attrs, attribute.String(AttrURLPath, tt.path))
+			}
+			got := s.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: ctx,
+				TraceID:       trace.TraceID{2},
+				Name:          tt.span,
+				Kind:          trace.SpanKindServer,
+				Attributes:    attrs,
+			})
+			if got.Decision != tt.want {
+				t.Errorf("Decision = %v, want %v", got.Decision, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		childErr  bool
+		rootSleep time.Duration
+		wantSpans int
+	}{
+		{"正常请求丢弃", false, 0, 0},
+		{"包含错误保留整条trace", true, 0, 2},
+		{"超过耗时阈值保留", false, 60 * time.Millisecond, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tail := newTailProcessor(sdktrace.NewSimpleSpanProcessor(exporter), 50*time.Millisecond, 0)
+			tp := sdktrace.NewTracerProvider(
+				sdktrace.WithSpanProcessor(tail),
+				sdktrace.WithSampler(newSampler(0, nil, true)),
+			)
+			defer tp.Shutdown(context.Background())
+			tracer := tp.Tracer("test")
+
+			ctx, root := tracer.Start(context.Background(), "GET /api/v1/category")
+			_, child := tracer.Start(ctx, "db.query")
+			if tt.childErr {
+				child.SetStatus(codes.Error, "connection refused")
+			}
+			child.End()
+			time.Sleep(tt.rootSleep)
+			root.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != tt.wantSpans {
+				t.Fatalf("exported spans = %d, want %d", len(spans), tt.wantSpans)
+			}
+			for _, span := range spans {
+				if !span.SpanContext.IsSampled() {
+					t.Errorf("span %s not marked sampled", span.Name)
+				}
+			}
+		})
+	}
+}
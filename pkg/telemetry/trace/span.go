@@ -39,54 +39,49 @@ func StartInternal(ctx context.Context) (context.Context, trace.Span) {
 
 // StartServer 开始服务端 Span（用于 HTTP/RPC 服务）
 func StartServer(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
-	ctx, span := Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer))
-	if len(attrs) > 0 {
-		span.SetAttributes(attrs...)
-	}
-	return ctx, span
+	// 属性在创建时传入，采样器可据此决策（如按路由采样）
+	return Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
 }
 
 // StartClient 开始客户端 Span（用于 HTTP/RPC 调用）
 func StartClient(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
-	ctx, span := Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient))
-	if len(attrs) > 0 {
-		span.SetAttributes(attrs...)
-	}
-	return ctx, span
+	return Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
 }
 
 // StartConsumer 开始消费者 Span（用于消息队列消费）
 func StartConsumer(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
-	ctx, span := Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindConsumer))
-	if len(attrs) > 0 {
-		span.SetAttributes(attrs...)
-	}
-	return ctx, span
+	return Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(attrs...))
 }
 
 // StartProducer 开始生产者 Span（用于消息队列生产）
 func StartProducer(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
-	ctx, span := Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindProducer))
-	if len(attrs) > 0 {
-		span.SetAttributes(attrs...)
-	}
-	return ctx, span
+	return Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(attrs...))
 }
 
-// End 结束 Span 并记录错误（如果有）
+// End 结束 Span 并记录错误（如果有），错误分类规则见 SetError
 func End(span trace.Span, err error) {
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+		SetError(span, err)
 	} else {
 		span.SetStatus(codes.Ok, "OK")
 	}
 	span.End()
 }
 
-// SetError 设置错误状态
+// SetError 记录错误：所有错误写入 error.code/error.category 属性，
+// 仅系统错误（errorx 1xxxx 及非 errorx 错误）记录异常事件并标记 Span 为 Error，
+// 参数、业务、认证错误属于正常业务结果，不计入错误率
 func SetError(span trace.Span, err error) {
-	if err != nil {
+	if err == nil {
+		return
+	}
+
+	code, category := ClassifyError(err)
+	span.SetAttributes(
+		attribute.Int(AttrErrorCode, code),
+		attribute.String(AttrErrorCategory, category),
+	)
+	if category == ErrorCategorySystem {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 	}
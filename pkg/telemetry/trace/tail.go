@@ -0,0 +1,202 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 尾部采样默认值
+const (
+	defaultTailLatency   = time.Second
+	defaultTailMaxTraces = 10000
+	tailMaxSpans         = 1000             // 单个 trace 最多缓存的 Span 数
+	tailTTL              = time.Minute      // 未结束 trace 及保留决策的有效期
+	tailCleanup          = 10 * time.Second // 清理间隔
+)
+
+// pendingTrace 等待根 Span 结束的 trace
+type pendingTrace struct {
+	spans   []sdktrace.ReadOnlySpan
+	keep    bool // 已出现错误 Span
+	updated time.Time
+}
+
+// tailProcessor 进程内尾部采样处理器
+// 已采样的 Span 直接交给下游；只记录未采样的 Span 按 trace 缓存，
+// 本地根 Span 结束时若 trace 中有错误或根 Span 耗时超过阈值，则整条 trace 标记为采样后交给下游
+type tailProcessor struct {
+	next      sdktrace.SpanProcessor
+	latency   time.Duration
+	maxTraces int
+
+	mu      sync.Mutex
+	pending map[trace.TraceID]*pendingTrace
+	kept    map[trace.TraceID]time.Time // 已决定保留的 trace，根 Span 之后结束的 Span 直接导出
+
+	keptTraces    atomic.Uint64
+	droppedTraces atomic.Uint64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newTailProcessor 创建尾部采样处理器
+func newTailProcessor(next sdktrace.SpanProcessor, latency time.Duration, maxTraces int) *tailProcessor {
+	if latency <= 0 {
+		latency = defaultTailLatency
+	}
+	if maxTraces <= 0 {
+		maxTraces = defaultTailMaxTraces
+	}
+	p := &tailProcessor{
+		next:      next,
+		latency:   latency,
+		maxTraces: maxTraces,
+		pending:   make(map[trace.TraceID]*pendingTrace),
+		kept:      make(map[trace.TraceID]time.Time),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go p.loop()
+	return p
+}
+
+// OnStart 转发给下游
+func (p *tailProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd 已采样直接转发，候选 Span 缓存到根 Span 结束后决策
+func (p *tailProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.next.OnEnd(s)
+		return
+	}
+
+	id := s.SpanContext().TraceID()
+	now := time.Now()
+
+	p.mu.Lock()
+	if _, ok := p.kept[id]; ok {
+		p.mu.Unlock()
+		p.next.OnEnd(sampledSpan{s})
+		return
+	}
+
+	pt := p.pending[id]
+	if pt == nil {
+		if len(p.pending) >= p.maxTraces {
+			p.mu.Unlock()
+			return
+		}
+		pt = &pendingTrace{}
+		p.pending[id] = pt
+	}
+	pt.updated = now
+	if len(pt.spans) < tailMaxSpans {
+		pt.spans = append(pt.spans, s)
+	}
+	if s.Status().Code == codes.Error {
+		pt.keep = true
+	}
+
+	// 本地根 Span 结束时决策
+	if s.Parent().IsValid() && !s.Parent().IsRemote() {
+		p.mu.Unlock()
+		return
+	}
+	if s.EndTime().Sub(s.StartTime()) >= p.latency {
+		pt.keep = true
+	}
+	delete(p.pending, id)
+	if pt.keep {
+		p.kept[id] = now
+	}
+	p.mu.Unlock()
+
+	p.finish(pt)
+}
+
+// finish 保留的 trace 交给下游
+func (p *tailProcessor) finish(pt *pendingTrace) {
+	if !pt.keep {
+		p.droppedTraces.Add(1)
+		return
+	}
+	p.keptTraces.Add(1)
+	for _, span := range pt.spans {
+		p.next.OnEnd(sampledSpan{span})
+	}
+}
+
+// loop 定期清理过期状态：根 Span 长时间未结束的 trace 按当前结果决策
+func (p *tailProcessor) loop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(tailCleanup)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.expire(now.Add(-tailTTL))
+		}
+	}
+}
+
+// expire 清理 before 之前未更新的状态
+func (p *tailProcessor) expire(before time.Time) {
+	var expired []*pendingTrace
+
+	p.mu.Lock()
+	for id, pt := range p.pending {
+		if pt.updated.Before(before) {
+			delete(p.pending, id)
+			expired = append(expired, pt)
+		}
+	}
+	for id, at := range p.kept {
+		if at.Before(before) {
+			delete(p.kept, id)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, pt := range expired {
+		p.finish(pt)
+	}
+}
+
+// Shutdown 输出未结束 trace 中需要保留的部分后关闭下游
+func (p *tailProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		<-p.done
+		p.expire(time.Now().Add(time.Hour))
+	})
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush 转发给下游
+func (p *tailProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampledSpan 将 SpanContext 标记为已采样的只读 Span，使 BatchSpanProcessor 导出尾部采样保留的 Span
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -6,56 +6,109 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
-var (
-	tracerProvider *sdktrace.TracerProvider
-	tracer         trace.Tracer
-)
+// std 默认实例，包级函数（Start、GetHealth、Close 等）使用
+var std = &Provider{}
+
+// Provider 链路追踪实例，持有 TracerProvider、Tracer 及导出状态
+// 零值及 nil 表示未启用：Start 返回 ctx 中已有的 Span（通常为 noop）
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+	export   *resilientExporter
+}
 
 // TraceConfig 链路追踪配置
 type TraceConfig struct {
 	Enabled  bool
-	Endpoint string
+	Endpoint string // 为空时使用导出器默认地址
 	Sampler  float64
-	Batcher  string
+	Batcher  string // otlp/otlpgrpc/otlphttp/jaeger/zipkin/stdout/file
+
+	Propagators []string      // 传播格式 tracecontext/baggage/b3/b3multi/jaeger，默认 tracecontext,baggage
+	Baggage     BaggageConfig // 入站 Baggage 过滤及复制到 Span 属性/日志字段的成员
+
+	TrustedProxies []string // 受信反向代理网段（CIDR 或 IP），只有来自这些地址的连接才读取 X-Forwarded-For（见 ClientIP）
+
+	Headers     map[string]string // 附加请求头（OTLP/Zipkin）
+	Compression string            // gzip，为空或 none 不压缩（OTLP/Zipkin）
+
+	TLSEnabled    bool   // 启用 TLS（Endpoint 为 https:// 时自动启用默认 TLS）
+	TLSSkipVerify bool   // 跳过服务端证书校验
+	CAFile        string // 服务端 CA 证书
+	CertFile      string // 客户端证书（mTLS）
+	KeyFile       string // 客户端私钥（mTLS）
+
+	FilePath string // file 导出器的输出文件，默认 logs/trace.jsonl
+
+	DownPolicy string // Collector 不可用时的策略：buffer（默认）/drop
+	BufferSize int    // buffer 策略缓存的最大 Span 数，默认 2048
+
+	SamplingRules []SamplingRule // 按路由采样规则，优先于 Sampler
+
+	TailSampling  bool          // 尾部采样：保留包含错误或耗时超过 TailLatency 的完整 trace
+	TailLatency   time.Duration // 默认 1s
+	TailMaxTraces int           // 同时缓存的最大 trace 数，默认 10000
 }
 
-// Init 初始化链路追踪
+// Init 初始化链路追踪：设置进程级 Propagator/Baggage 策略，创建实例并设为默认实例
 func Init(config TraceConfig, serviceName, version, environment string) error {
-	if !config.Enabled {
-		logx.Info("链路追踪未启用")
-		return nil
+	if err := Configure(config); err != nil {
+		return err
 	}
-
-	ctx := context.Background()
-
-	// 1. 创建 OTLP gRPC Exporter
-	conn, err := grpc.DialContext(ctx, config.Endpoint,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-		grpc.WithTimeout(5*time.Second),
-	)
+	p, err := New(config, serviceName, version, environment)
 	if err != nil {
-		logx.Errorf("连接 OTLP endpoint 失败: %v", err)
 		return err
 	}
+	SetDefault(p)
+	return nil
+}
 
-	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+// Configure 设置进程级 Propagator（otel 全局）、Baggage 策略和受信代理网段
+// 未启用追踪时也需设置，上游 trace 上下文仍可透传给下游
+func Configure(config TraceConfig) error {
+	propagator, err := newPropagator(config.Propagators)
 	if err != nil {
-		logx.Errorf("创建 OTLP exporter 失败: %v", err)
+		logx.Errorf("创建链路追踪 propagator 失败: %v", err)
+		return err
+	}
+	otel.SetTextMapPropagator(propagator)
+	if err := configureBaggage(config.Baggage); err != nil {
+		logx.Errorf("链路追踪 Baggage 受信网段配置错误: %v", err)
 		return err
 	}
+	if err := configureProxies(config.TrustedProxies); err != nil {
+		logx.Errorf("受信代理网段配置错误: %v", err)
+		return err
+	}
+	return nil
+}
+
+// New 创建链路追踪实例，未启用时返回零值实例
+// 不修改全局状态：Propagator/Baggage 策略由 Configure 设置，otel 全局 Provider 由 SetDefault 设置
+func New(config TraceConfig, serviceName, version, environment string) (*Provider, error) {
+	if !config.Enabled {
+		logx.Info("链路追踪未启用")
+		return &Provider{}, nil
+	}
+
+	ctx := context.Background()
+
+	// 1. 按 Batcher 创建 Exporter（不等待 Collector 连接，仅配置错误时失败）
+	exporter, err := newExporter(ctx, config)
+	if err != nil {
+		logx.Errorf("创建链路追踪 exporter 失败 [batcher=%s]: %v", config.Batcher, err)
+		return nil, err
+	}
+	export := newResilientExporter(exporter, config)
 
 	// 2. 创建 Resource
 	res, err := resource.New(ctx,
@@ -67,55 +120,123 @@ func Init(config TraceConfig, serviceName, version, environment string) error {
 	)
 	if err != nil {
 		logx.Errorf("创建 resource 失败: %v", err)
-		return err
+		_ = export.Shutdown(ctx)
+		return nil, err
 	}
 
 	// 3. 创建 TracerProvider
-	tracerProvider = sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter,
-			sdktrace.WithMaxQueueSize(1000),
-			sdktrace.WithMaxExportBatchSize(100),
-			sdktrace.WithBatchTimeout(5*time.Second),
-		),
+	var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(newMaskExporter(export),
+		sdktrace.WithMaxQueueSize(1000),
+		sdktrace.WithMaxExportBatchSize(100),
+		sdktrace.WithBatchTimeout(5*time.Second),
+	)
+	if config.TailSampling {
+		processor = newTailProcessor(processor, config.TailLatency, config.TailMaxTraces)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(baggageProcessor{}),
+		sdktrace.WithSpanProcessor(processor),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(config.Sampler)),
+		sdktrace.WithSampler(newSampler(config.Sampler, config.SamplingRules, config.TailSampling)),
 	)
 
-	// 4. 设置全局 TracerProvider
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+	logx.Infof("链路追踪初始化完成 [batcher=%s, endpoint=%s, sampler=%.2f, rules=%d, tail=%v]",
+		config.Batcher, config.Endpoint, config.Sampler, len(config.SamplingRules), config.TailSampling)
 
-	// 5. 创建 Tracer
-	tracer = tracerProvider.Tracer(serviceName)
+	return &Provider{provider: tp, tracer: tp.Tracer(serviceName), export: export}, nil
+}
 
-	logx.Infof("链路追踪初始化完成 [endpoint=%s, sampler=%.2f]",
-		config.Endpoint, config.Sampler)
+// NewWithTracerProvider 使用已有的 TracerProvider 创建实例（用于测试，见 tracetest 包）
+func NewWithTracerProvider(tp *sdktrace.TracerProvider) *Provider {
+	return &Provider{provider: tp, tracer: tp.Tracer("idrm/pkg/telemetry/trace")}
+}
 
-	return nil
+// Default 返回默认实例
+func Default() *Provider {
+	return std
+}
+
+// SetDefault 设置默认实例及 otel 全局 TracerProvider（供 otel.Tracer 使用方，如 httpclient），
+// 返回恢复原值的函数；p 为 nil 时恢复为未启用
+// 恢复时 otel 全局 Provider 设为原默认实例的 TracerProvider（原实例未启用时为 noop），
+// 绕过本包直接设置的全局 Provider 不会被恢复
+func SetDefault(p *Provider) (restore func()) {
+	if p == nil {
+		p = &Provider{}
+	}
+	prev := std
+
+	std = p
+	otel.SetTracerProvider(p.TracerProvider())
+	return func() {
+		std = prev
+		otel.SetTracerProvider(prev.TracerProvider())
+	}
+}
+
+// SetTracerProvider 使用 tp 替换默认实例，返回恢复原值的函数（用于测试，见 tracetest 包）
+func SetTracerProvider(tp *sdktrace.TracerProvider) (restore func()) {
+	return SetDefault(NewWithTracerProvider(tp))
 }
 
 // Start 开始一个 Span
-func Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
-	if tracer == nil {
+func (p *Provider) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if p == nil || p.tracer == nil {
 		// 如果未初始化，返回 noop span
 		return ctx, trace.SpanFromContext(ctx)
 	}
-	return tracer.Start(ctx, spanName, opts...)
+	return p.tracer.Start(ctx, spanName, opts...)
+}
+
+// Tracer 返回 Tracer，未启用时为 nil
+func (p *Provider) Tracer() trace.Tracer {
+	if p == nil {
+		return nil
+	}
+	return p.tracer
+}
+
+// TracerProvider 返回 TracerProvider，未启用时为 noop
+func (p *Provider) TracerProvider() trace.TracerProvider {
+	if p == nil || p.provider == nil {
+		return noop.NewTracerProvider()
+	}
+	return p.provider
+}
+
+// Health 返回导出状态，未启用时 State 为 disabled
+func (p *Provider) Health() Health {
+	if p == nil || p.export == nil {
+		return Health{State: StateDisabled}
+	}
+	return p.export.Health()
+}
+
+// Shutdown 导出剩余 Span 并关闭
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil || p.provider == nil {
+		return nil
+	}
+	logx.Info("关闭链路追踪...")
+	return p.provider.Shutdown(ctx)
+}
+
+// Start 使用默认实例开始一个 Span
+func Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return std.Start(ctx, spanName, opts...)
 }
 
-// Tracer 获取全局 Tracer
+// Tracer 获取默认实例的 Tracer
 func Tracer() trace.Tracer {
-	return tracer
+	return std.Tracer()
 }
 
-// Close 关闭链路追踪
+// GetHealth 返回默认实例的导出状态，未启用时 State 为 disabled
+func GetHealth() Health {
+	return std.Health()
+}
+
+// Close 关闭默认实例
 func Close(ctx context.Context) error {
-	if tracerProvider != nil {
-		logx.Info("关闭链路追踪...")
-		return tracerProvider.Shutdown(ctx)
-	}
-	return nil
+	return std.Shutdown(ctx)
 }
@@ -0,0 +1,209 @@
+// Package tracetest 链路追踪测试工具
+//
+// trace 包使用包级 TracerProvider/Tracer，未初始化时 trace.Start 返回 noop Span，
+// 单元测试无法断言 Span。本包将内存导出器安装到 trace 包（及 otel 全局），
+// 提供按名称查找、属性、父子关系、状态断言，测试结束时自动恢复原 Provider。
+//
+//	rec := tracetest.New(t)
+//	err := logic.Publish(ctx, id)
+//	rec.Span(t, "category.Publish").
+//	    HasAttribute("category.id", id).
+//	    HasStatus(codes.Ok).
+//	    HasParent("POST /api/v1/category/:id/publish")
+package tracetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"idrm/pkg/telemetry/trace"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	sdktracetest "go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Recorder 内存 Span 记录器
+type Recorder struct {
+	exporter *sdktracetest.InMemoryExporter
+	provider *sdktrace.TracerProvider
+	tracer   *trace.Provider
+}
+
+// New 安装记录器（全部采样，Span 结束时同步记录），测试结束时恢复原 Provider
+// 记录器替换的是包级全局变量，使用它的测试不能调用 t.Parallel
+func New(t testing.TB) *Recorder {
+	t.Helper()
+
+	r := &Recorder{exporter: sdktracetest.NewInMemoryExporter()}
+	r.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSyncer(r.exporter),
+	)
+	r.tracer = trace.NewWithTracerProvider(r.provider)
+	restore := trace.SetDefault(r.tracer)
+	t.Cleanup(func() {
+		restore()
+		_ = r.provider.Shutdown(context.Background())
+	})
+	return r
+}
+
+// Provider 返回记录器的链路追踪实例（已设为默认实例），用于注入 telemetry.Provider 或中间件
+func (r *Recorder) Provider() *trace.Provider {
+	return r.tracer
+}
+
+// Spans 返回已结束的 Span（按结束顺序）
+func (r *Recorder) Spans() sdktracetest.SpanStubs {
+	return r.exporter.GetSpans()
+}
+
+// Names 返回已结束 Span 的名称（按结束顺序）
+func (r *Recorder) Names() []string {
+	spans := r.Spans()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Reset 清空已记录的 Span
+func (r *Recorder) Reset() {
+	r.exporter.Reset()
+}
+
+// Find 按名称查找最后结束的 Span
+func (r *Recorder) Find(name string) (sdktracetest.SpanStub, bool) {
+	spans := r.Spans()
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].Name == name {
+			return spans[i], true
+		}
+	}
+	return sdktracetest.SpanStub{}, false
+}
+
+// AssertCount 断言已结束 Span 数量
+func (r *Recorder) AssertCount(t testing.TB, want int) {
+	t.Helper()
+	if got := len(r.Spans()); got != want {
+		t.Errorf("span count = %d, want %d (spans: %v)", got, want, r.Names())
+	}
+}
+
+// AssertNone 断言不存在指定名称的 Span
+func (r *Recorder) AssertNone(t testing.TB, name string) {
+	t.Helper()
+	if _, ok := r.Find(name); ok {
+		t.Errorf("span %q recorded, want none", name)
+	}
+}
+
+// Span 按名称查找 Span 并返回断言器，不存在时测试立即失败
+func (r *Recorder) Span(t testing.TB, name string) *SpanAssert {
+	t.Helper()
+	span, ok := r.Find(name)
+	if !ok {
+		t.Fatalf("span %q not found (spans: %v)", name, r.Names())
+	}
+	return &SpanAssert{t: t, rec: r, Span: span}
+}
+
+// SpanAssert Span 断言器，断言失败时记录错误并继续（t.Errorf），支持链式调用
+type SpanAssert struct {
+	t   testing.TB
+	rec *Recorder
+
+	Span sdktracetest.SpanStub
+}
+
+// Attribute 返回属性值
+func (a *SpanAssert) Attribute(key string) (attribute.Value, bool) {
+	for _, kv := range a.Span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+// HasAttribute 断言属性值，want 按字符串形式比较（如 7 与 int64 属性 7 相等）
+func (a *SpanAssert) HasAttribute(key string, want any) *SpanAssert {
+	a.t.Helper()
+	v, ok := a.Attribute(key)
+	if !ok {
+		a.t.Errorf("span %q: attribute %q missing", a.Span.Name, key)
+		return a
+	}
+	if got := v.Emit(); got != fmt.Sprint(want) {
+		a.t.Errorf("span %q: attribute %q = %s, want %v", a.Span.Name, key, got, want)
+	}
+	return a
+}
+
+// NoAttribute 断言不存在属性
+func (a *SpanAssert) NoAttribute(key string) *SpanAssert {
+	a.t.Helper()
+	if v, ok := a.Attribute(key); ok {
+		a.t.Errorf("span %q: attribute %q = %s, want none", a.Span.Name, key, v.Emit())
+	}
+	return a
+}
+
+// HasStatus 断言状态码
+func (a *SpanAssert) HasStatus(want codes.Code) *SpanAssert {
+	a.t.Helper()
+	if a.Span.Status.Code != want {
+		a.t.Errorf("span %q: status = %v (%s), want %v", a.Span.Name, a.Span.Status.Code, a.Span.Status.Description, want)
+	}
+	return a
+}
+
+// HasKind 断言 Span 类型
+func (a *SpanAssert) HasKind(want oteltrace.SpanKind) *SpanAssert {
+	a.t.Helper()
+	if a.Span.SpanKind != want {
+		a.t.Errorf("span %q: kind = %v, want %v", a.Span.Name, a.Span.SpanKind, want)
+	}
+	return a
+}
+
+// HasEvent 断言存在指定名称的事件（错误事件名称为 exception）
+func (a *SpanAssert) HasEvent(name string) *SpanAssert {
+	a.t.Helper()
+	for _, e := range a.Span.Events {
+		if e.Name == name {
+			return a
+		}
+	}
+	a.t.Errorf("span %q: event %q missing", a.Span.Name, name)
+	return a
+}
+
+// HasParent 断言父 Span（按名称在已记录 Span 中查找，父 Span 需已结束）
+func (a *SpanAssert) HasParent(name string) *SpanAssert {
+	a.t.Helper()
+	parent, ok := a.rec.Find(name)
+	if !ok {
+		a.t.Errorf("span %q: parent %q not found (spans: %v)", a.Span.Name, name, a.rec.Names())
+		return a
+	}
+	if a.Span.Parent.SpanID() != parent.SpanContext.SpanID() || a.Span.Parent.TraceID() != parent.SpanContext.TraceID() {
+		a.t.Errorf("span %q: parent = %s, want %q (%s)", a.Span.Name, a.Span.Parent.SpanID(), name, parent.SpanContext.SpanID())
+	}
+	return a
+}
+
+// IsRoot 断言为本进程内的根 Span（无父 Span 或父 Span 来自远程调用方）
+func (a *SpanAssert) IsRoot() *SpanAssert {
+	a.t.Helper()
+	if a.Span.Parent.IsValid() && !a.Span.Parent.IsRemote() {
+		a.t.Errorf("span %q: parent = %s, want root", a.Span.Name, a.Span.Parent.SpanID())
+	}
+	return a
+}
@@ -0,0 +1,77 @@
+package tracetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"idrm/pkg/errorx"
+	"idrm/pkg/telemetry/trace"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := New(t)
+
+	ctx, root := trace.StartServer(context.Background(), "GET /api/v1/category/:id",
+		attribute.String("http.route", "/api/v1/category/:id"))
+	err := trace.Run(ctx, "category.Get", func(ctx context.Context) error {
+		return errorx.NewWithCode(errorx.ErrCodeNotFound)
+	}, attribute.Int64("category.id", 7))
+	trace.End(root, err)
+
+	rec.AssertCount(t, 2)
+	rec.Span(t, "GET /api/v1/category/:id").
+		HasKind(oteltrace.SpanKindServer).
+		HasAttribute("http.route", "/api/v1/category/:id").
+		IsRoot()
+	rec.Span(t, "category.Get").
+		HasKind(oteltrace.SpanKindInternal).
+		HasAttribute("category.id", 7).
+		HasAttribute(trace.AttrErrorCategory, trace.ErrorCategoryBusiness).
+		HasStatus(codes.Unset).
+		HasParent("GET /api/v1/category/:id")
+
+	// Reset 后重新记录
+	rec.Reset()
+	rec.AssertCount(t, 0)
+
+	_, span := trace.StartClient(context.Background(), "GET catalog")
+	trace.End(span, errors.New("connection refused"))
+	rec.Span(t, "GET catalog").HasStatus(codes.Error).HasEvent("exception").NoAttribute("http.route")
+	rec.AssertNone(t, "category.Get")
+}
+
+func TestRecorderRestore(t *testing.T) {
+	t.Run("安装", func(t *testing.T) {
+		rec := New(t)
+		_, span := trace.Start(context.Background(), "inner")
+		span.End()
+		rec.AssertCount(t, 1)
+	})
+
+	// 子测试结束后恢复为未初始化状态，Start 及 otel 全局 Tracer 返回 noop Span
+	_, span := trace.Start(context.Background(), "outer")
+	if span.SpanContext().IsValid() {
+		t.Error("span after restore is recording, want noop")
+	}
+	_, span = otel.Tracer("test").Start(context.Background(), "global")
+	if span.SpanContext().IsValid() {
+		t.Error("global span after restore is recording, want noop")
+	}
+
+	// 嵌套安装时恢复为外层记录器（默认实例及 otel 全局）
+	outer := New(t)
+	t.Run("嵌套", func(t *testing.T) {
+		New(t)
+	})
+	_, span = trace.Start(context.Background(), "after-nested")
+	span.End()
+	_, span = otel.Tracer("test").Start(context.Background(), "global-after-nested")
+	span.End()
+	outer.AssertCount(t, 2)
+}